@@ -0,0 +1,59 @@
+package addon
+
+import (
+	"fmt"
+	"sort"
+)
+
+// configSchema is the part of an addon configuration schema we render: objects without additional
+// properties and string leaves (nil). Checked in, EKS would only refuse the values halfway through the update
+type configSchema map[string]configSchema
+
+// vpcCniSchema comes from aws eks describe-addon-configuration --addon-name vpc-cni (v1.14 and later)
+var vpcCniSchema = configSchema{
+	"enableNetworkPolicy": nil,
+	"env": {
+		"AWS_VPC_K8S_CNI_CUSTOM_NETWORK_CFG": nil,
+		"ENABLE_POD_ENI":                     nil,
+		"ENABLE_PREFIX_DELEGATION":           nil,
+		"ENI_CONFIG_LABEL_DEF":               nil,
+		"MINIMUM_IP_TARGET":                  nil,
+		"WARM_IP_TARGET":                     nil,
+		"WARM_PREFIX_TARGET":                 nil,
+	},
+	"init": {
+		"env": {
+			"DISABLE_TCP_EARLY_DEMUX": nil,
+		},
+	},
+}
+
+// validate checks decoded JSON values, path names the object in the errors
+func (s configSchema) validate(path string, values map[string]interface{}) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, ok := s[key]
+		if !ok {
+			return fmt.Errorf("%s%s isn't in the addon configuration schema", path, key)
+		}
+		if property == nil {
+			if _, ok := values[key].(string); !ok {
+				return fmt.Errorf("%s%s must be a string", path, key)
+			}
+			continue
+		}
+		object, ok := values[key].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s%s must be an object", path, key)
+		}
+		if err := property.validate(path+key+".", object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
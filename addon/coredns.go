@@ -0,0 +1,112 @@
+// An add-on is software that provides supporting operational capabilities to Kubernetes applications
+// And is "COMPLETELY INSTALED BY AWS"
+package addon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// CoreDnsArgs.ComputeType values
+const CoreDnsComputeTypeFargate = "Fargate"
+
+type CoreDns struct {
+	pulumi.ResourceState
+}
+
+type CoreDnsArgs struct {
+	ClusterName pulumi.StringInput `json:"-"`
+	// Cluster version the addon version is resolved for, nil lets EKS pick
+	KubernetesVersion pulumi.StringInput `json:"-"`
+	// 0 keeps the addon default (2)
+	Replicas int `json:"replicas"`
+	// Kubernetes affinity as in a pod spec, nil keeps the addon default (spread over nodes and zones)
+	Affinity    map[string]interface{} `json:"affinity"`
+	Tolerations []Toleration           `json:"tolerations"`
+	// CoreDnsComputeTypeFargate drops the ec2 compute-type annotation so a Fargate profile picks the pods
+	ComputeType string `json:"computeType"`
+}
+
+// Toleration of a pod spec
+type Toleration struct {
+	Key      string `json:"key,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Effect   string `json:"effect,omitempty"`
+}
+
+func (args *CoreDnsArgs) validate() error {
+	if args.Replicas < 0 {
+		return errors.New("Replicas can't be negative")
+	}
+	if args.ComputeType != "" && args.ComputeType != CoreDnsComputeTypeFargate {
+		return fmt.Errorf("ComputeType %q can only be %s", args.ComputeType, CoreDnsComputeTypeFargate)
+	}
+	return nil
+}
+
+// configurationValues follows aws eks describe-addon-configuration --addon-name coredns
+func (args *CoreDnsArgs) configurationValues() (string, error) {
+	values := map[string]interface{}{}
+
+	if args.Replicas > 0 {
+		values["replicaCount"] = args.Replicas
+	}
+	if args.Affinity != nil {
+		values["affinity"] = args.Affinity
+	}
+	if len(args.Tolerations) > 0 {
+		values["tolerations"] = args.Tolerations
+	}
+	if args.ComputeType != "" {
+		values["computeType"] = args.ComputeType
+	}
+
+	rendered, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("coredns configuration values: %w", err)
+	}
+	return string(rendered), nil
+}
+
+func NewCoreDns(ctx *pulumi.Context, name string, args *CoreDnsArgs, opts ...pulumi.ResourceOption) (*CoreDns, error) {
+	componentResource := &CoreDns{}
+
+	if args == nil {
+		args = &CoreDnsArgs{}
+	}
+
+	err := args.validate()
+	if err != nil {
+		return nil, fmt.Errorf("coredns %q: %w", name, err)
+	}
+
+	configurationValues, err := args.configurationValues()
+	if err != nil {
+		return nil, fmt.Errorf("coredns %q: %w", name, err)
+	}
+
+	// <package>:<module>:<type>
+	err = ctx.RegisterComponentResource("my-own-cluster:addon:CoreDns", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = NewManagedAddon(ctx, name, &ManagedAddonArgs{
+		ClusterName:         args.ClusterName,
+		AddonName:           "coredns",
+		KubernetesVersion:   args.KubernetesVersion,
+		ConfigurationValues: pulumi.String(configurationValues),
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
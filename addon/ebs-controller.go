@@ -5,10 +5,7 @@ package addon
 import (
 	"fmt"
 
-	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
-	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
-	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 )
 
 type EbsController struct {
@@ -18,6 +15,21 @@ type EbsController struct {
 type EbsControllerArgs struct {
 	ClusterName            pulumi.StringInput
 	IssuerUrlWithoutPrefix pulumi.StringInput
+	// Cluster version the addon version is resolved for, nil lets EKS pick
+	KubernetesVersion pulumi.StringInput
+	// Pins the addon version instead
+	Version string
+	// cluster.IdentityModeIrsa (default) or cluster.IdentityModePodIdentity
+	IdentityMode string
+	// Customer key for the volumes, nil uses the aws/ebs key
+	KmsKeyArn pulumi.StringInput
+	// Of the storage classes and snapshots, ReclaimPolicyDelete (default) or ReclaimPolicyRetain
+	ReclaimPolicy string
+	// Adds an "io2" class next to the default "gp3" one
+	Io2StorageClass bool
+	Io2IopsPerGb    int
+	// snapshot-controller addon and a VolumeSnapshotClass
+	Snapshots bool
 }
 
 func NewEbsController(ctx *pulumi.Context, name string, args *EbsControllerArgs, opts ...pulumi.ResourceOption) (*EbsController, error) {
@@ -27,8 +39,19 @@ func NewEbsController(ctx *pulumi.Context, name string, args *EbsControllerArgs,
 		args = &EbsControllerArgs{}
 	}
 
-	cfg := config.New(ctx, "")
-	account := cfg.GetSecret("account")
+	if args.ReclaimPolicy == "" {
+		args.ReclaimPolicy = ReclaimPolicyDelete
+	}
+	if args.ReclaimPolicy != ReclaimPolicyDelete && args.ReclaimPolicy != ReclaimPolicyRetain {
+		return nil, fmt.Errorf("ebs controller %q: ReclaimPolicy %q must be %s or %s", name, args.ReclaimPolicy, ReclaimPolicyDelete, ReclaimPolicyRetain)
+	}
+	if args.Io2IopsPerGb == 0 {
+		args.Io2IopsPerGb = defaultIo2IopsPerGb
+	}
+	// io2 goes up to 500 iops per GiB
+	if args.Io2IopsPerGb < 0 || args.Io2IopsPerGb > 500 {
+		return nil, fmt.Errorf("ebs controller %q: Io2IopsPerGb must be between 1 and 500", name)
+	}
 
 	// <package>:<module>:<type>
 	err := ctx.RegisterComponentResource("k8s-nodes:addon:EbsController", name, componentResource, opts...)
@@ -36,44 +59,45 @@ func NewEbsController(ctx *pulumi.Context, name string, args *EbsControllerArgs,
 		return nil, err
 	}
 
-	trustedPolicy := pulumi.Sprintf(`{
-  "Version": "2012-10-17",
-  "Statement": [
-    {
-      "Effect": "Allow",
-      "Principal": {
-        "Federated": "arn:aws:iam::%s:oidc-provider/%s"
-      },
-      "Action": "sts:AssumeRoleWithWebIdentity",
-      "Condition": {
-        "StringEquals": {
-          "%s:aud": "sts.amazonaws.com",
-          "%s:sub": "system:serviceaccount:kube-system:ebs-csi-controller-sa"
-        }
-      }
-    }
-  ]
-}`, account, args.IssuerUrlWithoutPrefix, args.IssuerUrlWithoutPrefix, args.IssuerUrlWithoutPrefix)
-
-	ebsControllerRole, err := iam.NewRole(ctx, fmt.Sprintf("%s-ebs-controller-role", name), &iam.RoleArgs{
-		AssumeRolePolicy:  trustedPolicy,
-		ManagedPolicyArns: pulumi.ToStringArray([]string{"arn:aws:iam::aws:policy/service-role/AmazonEBSCSIDriverPolicy"}),
+	inlinePolicies := map[string]pulumi.StringInput{}
+	if args.KmsKeyArn != nil {
+		inlinePolicies["ebs-csi-kms"] = ebsKmsPolicy(args.KmsKeyArn)
+	}
+
+	driver, err := NewManagedAddon(ctx, name, &ManagedAddonArgs{
+		ClusterName:            args.ClusterName,
+		AddonName:              "aws-ebs-csi-driver",
+		KubernetesVersion:      args.KubernetesVersion,
+		Version:                args.Version,
+		ServiceAccount:         "kube-system:ebs-csi-controller-sa",
+		IssuerUrlWithoutPrefix: args.IssuerUrlWithoutPrefix,
+		IdentityMode:           args.IdentityMode,
+		ManagedPolicyArns:      []string{"arn:aws:iam::aws:policy/service-role/AmazonEBSCSIDriverPolicy"},
+		InlinePolicies:         inlinePolicies,
+		// the baseline EbsController created them directly
+		legacyAddonName: fmt.Sprintf("%s-controller-addon", name),
+		legacyRoleName:  fmt.Sprintf("%s-ebs-controller-role", name),
+		legacyParent:    componentResource,
 	}, pulumi.Parent(componentResource))
 
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = eks.NewAddon(ctx, fmt.Sprintf("%s-controller-addon", name), &eks.AddonArgs{
-		ClusterName:           args.ClusterName,
-		AddonName:             pulumi.String("aws-ebs-csi-driver"),
-		ServiceAccountRoleArn: ebsControllerRole.Arn,
-	}, pulumi.Parent(componentResource))
+	err = newEbsStorageClasses(ctx, name, args, driver, componentResource)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if args.Snapshots {
+		err = newEbsSnapshots(ctx, name, args, driver, componentResource)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
 
 	return componentResource, nil
@@ -0,0 +1,158 @@
+package addon
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apiextensions"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	storagev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/storage/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+const ebsCsiProvisioner = "ebs.csi.aws.com"
+
+// EbsControllerArgs.ReclaimPolicy values
+const (
+	ReclaimPolicyDelete = "Delete"
+	ReclaimPolicyRetain = "Retain"
+)
+
+const defaultIo2IopsPerGb = 50
+
+// ebsKmsPolicy lets the driver encrypt volumes with a customer key, grants are how EC2 uses it for attached volumes
+// https://docs.aws.amazon.com/eks/latest/userguide/csi-iam-role.html
+func ebsKmsPolicy(kmsKeyArn pulumi.StringInput) pulumi.StringOutput {
+	return pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": [
+        "kms:CreateGrant",
+        "kms:ListGrants",
+        "kms:RevokeGrant"
+      ],
+      "Resource": ["%s"],
+      "Condition": {
+        "Bool": {
+          "kms:GrantIsForAWSResource": "true"
+        }
+      }
+    },
+    {
+      "Effect": "Allow",
+      "Action": [
+        "kms:Encrypt",
+        "kms:Decrypt",
+        "kms:ReEncrypt*",
+        "kms:GenerateDataKey*",
+        "kms:DescribeKey"
+      ],
+      "Resource": ["%s"]
+    }
+  ]
+}`, kmsKeyArn, kmsKeyArn)
+}
+
+// newEbsStorageClasses makes an encrypted gp3 class the default one (EKS ships gp2 as default) and an optional io2 class
+func newEbsStorageClasses(ctx *pulumi.Context, name string, args *EbsControllerArgs, driver pulumi.Resource, parent pulumi.Resource) error {
+	gp3Parameters := pulumi.StringMap{
+		"type":      pulumi.String("gp3"),
+		"encrypted": pulumi.String("true"),
+	}
+	if args.KmsKeyArn != nil {
+		gp3Parameters["kmsKeyId"] = args.KmsKeyArn
+	}
+
+	_, err := storagev1.NewStorageClass(ctx, fmt.Sprintf("%s-gp3-storage-class", name), &storagev1.StorageClassArgs{
+		Metadata: metav1.ObjectMetaArgs{
+			Name: pulumi.StringPtr("gp3"),
+			Annotations: pulumi.StringMap{
+				"storageclass.kubernetes.io/is-default-class": pulumi.String("true"),
+			},
+		},
+		Provisioner:          pulumi.String(ebsCsiProvisioner),
+		Parameters:           gp3Parameters,
+		ReclaimPolicy:        pulumi.StringPtr(args.ReclaimPolicy),
+		VolumeBindingMode:    pulumi.StringPtr("WaitForFirstConsumer"),
+		AllowVolumeExpansion: pulumi.BoolPtr(true),
+	}, pulumi.Parent(parent), pulumi.DependsOn([]pulumi.Resource{driver}))
+
+	if err != nil {
+		return err
+	}
+
+	// two default classes make PVCs without class fail
+	_, err = storagev1.NewStorageClassPatch(ctx, fmt.Sprintf("%s-gp2-not-default", name), &storagev1.StorageClassPatchArgs{
+		Metadata: metav1.ObjectMetaPatchArgs{
+			Name: pulumi.StringPtr("gp2"),
+			Annotations: pulumi.StringMap{
+				"storageclass.kubernetes.io/is-default-class": pulumi.String("false"),
+				"pulumi.com/patchForce":                       pulumi.String("true"),
+			},
+		},
+	}, pulumi.Parent(parent), pulumi.DependsOn([]pulumi.Resource{driver}))
+
+	if err != nil {
+		return err
+	}
+
+	if !args.Io2StorageClass {
+		return nil
+	}
+
+	io2Parameters := pulumi.StringMap{
+		"type":      pulumi.String("io2"),
+		"iopsPerGB": pulumi.String(fmt.Sprint(args.Io2IopsPerGb)),
+		"encrypted": pulumi.String("true"),
+		// small volumes still get the minimum iops
+		"allowAutoIOPSPerGBIncrease": pulumi.String("true"),
+	}
+	if args.KmsKeyArn != nil {
+		io2Parameters["kmsKeyId"] = args.KmsKeyArn
+	}
+
+	_, err = storagev1.NewStorageClass(ctx, fmt.Sprintf("%s-io2-storage-class", name), &storagev1.StorageClassArgs{
+		Metadata: metav1.ObjectMetaArgs{
+			Name: pulumi.StringPtr("io2"),
+		},
+		Provisioner:          pulumi.String(ebsCsiProvisioner),
+		Parameters:           io2Parameters,
+		ReclaimPolicy:        pulumi.StringPtr(args.ReclaimPolicy),
+		VolumeBindingMode:    pulumi.StringPtr("WaitForFirstConsumer"),
+		AllowVolumeExpansion: pulumi.BoolPtr(true),
+	}, pulumi.Parent(parent), pulumi.DependsOn([]pulumi.Resource{driver}))
+
+	return err
+}
+
+// newEbsSnapshots installs the snapshot-controller addon, which brings the VolumeSnapshot CRDs, and the EBS snapshot class
+func newEbsSnapshots(ctx *pulumi.Context, name string, args *EbsControllerArgs, driver pulumi.Resource, parent pulumi.Resource) error {
+	snapshotController, err := NewManagedAddon(ctx, fmt.Sprintf("%s-snapshot-controller", name), &ManagedAddonArgs{
+		ClusterName:       args.ClusterName,
+		AddonName:         "snapshot-controller",
+		KubernetesVersion: args.KubernetesVersion,
+	}, pulumi.Parent(parent))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = apiextensions.NewCustomResource(ctx, fmt.Sprintf("%s-volume-snapshot-class", name), &apiextensions.CustomResourceArgs{
+		ApiVersion: pulumi.String("snapshot.storage.k8s.io/v1"),
+		Kind:       pulumi.String("VolumeSnapshotClass"),
+		Metadata: metav1.ObjectMetaArgs{
+			Name: pulumi.StringPtr("ebs"),
+			Annotations: pulumi.StringMap{
+				"snapshot.storage.kubernetes.io/is-default-class": pulumi.String("true"),
+			},
+		},
+		OtherFields: kubernetes.UntypedArgs{
+			"driver":         pulumi.String(ebsCsiProvisioner),
+			"deletionPolicy": pulumi.String(args.ReclaimPolicy),
+		},
+	}, pulumi.Parent(parent), pulumi.DependsOn([]pulumi.Resource{snapshotController, driver}))
+
+	return err
+}
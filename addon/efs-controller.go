@@ -0,0 +1,177 @@
+// An add-on is software that provides supporting operational capabilities to Kubernetes applications
+// And is "COMPLETELY INSTALED BY AWS"
+package addon
+
+import (
+	"fmt"
+
+	"k8s-cluster-own/network"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/efs"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	storagev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/storage/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// EfsController gives ReadWriteMany volumes: an encrypted file system reachable from every private subnet,
+// the aws-efs-csi-driver addon and an "efs" StorageClass creating an access point per volume
+type EfsController struct {
+	pulumi.ResourceState
+	FileSystem *efs.FileSystem
+}
+
+type EfsControllerArgs struct {
+	ClusterName            pulumi.StringInput
+	IssuerUrlWithoutPrefix pulumi.StringInput
+	// Cluster version the addon version is resolved for, nil lets EKS pick
+	KubernetesVersion pulumi.StringInput
+	VpcId             pulumi.StringInput
+	// A mount target per subnet, one subnet per zone (network.Network.PrivateSubnets)
+	Subnets []network.Subnet
+	// NFS is allowed from it, usually the cluster node security group
+	NodeSecurityGroupId pulumi.StringInput
+	// Customer key for the file system, nil uses the aws/elasticfilesystem key
+	KmsKeyArn pulumi.StringInput
+}
+
+func NewEfsController(ctx *pulumi.Context, name string, args *EfsControllerArgs, opts ...pulumi.ResourceOption) (*EfsController, error) {
+	componentResource := &EfsController{}
+
+	if args == nil {
+		args = &EfsControllerArgs{}
+	}
+
+	if len(args.Subnets) == 0 {
+		return nil, fmt.Errorf("efs controller %q: at least one subnet is required", name)
+	}
+	// EFS refuses a second mount target in a zone
+	subnetByZone := map[string]int{}
+	for i, subnet := range args.Subnets {
+		if subnet.AvailabilityZone == "" {
+			return nil, fmt.Errorf("efs controller %q: subnet %d has no availability zone", name, i)
+		}
+		if j, ok := subnetByZone[subnet.AvailabilityZone]; ok {
+			return nil, fmt.Errorf("efs controller %q: subnets %d and %d are both in %s, one subnet per zone is allowed", name, j, i, subnet.AvailabilityZone)
+		}
+		subnetByZone[subnet.AvailabilityZone] = i
+	}
+	if args.NodeSecurityGroupId == nil {
+		return nil, fmt.Errorf("efs controller %q: NodeSecurityGroupId is required", name)
+	}
+
+	// <package>:<module>:<type>
+	err := ctx.RegisterComponentResource("k8s-nodes:addon:EfsController", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	fileSystemArgs := &efs.FileSystemArgs{
+		Encrypted:       pulumi.BoolPtr(true),
+		PerformanceMode: pulumi.StringPtr("generalPurpose"),
+		ThroughputMode:  pulumi.StringPtr("elastic"),
+		LifecyclePolicies: efs.FileSystemLifecyclePolicyArray{
+			efs.FileSystemLifecyclePolicyArgs{
+				TransitionToIa: pulumi.StringPtr("AFTER_30_DAYS"),
+			},
+		},
+		Tags: pulumi.StringMap{
+			"Name": pulumi.Sprintf("%s-%s", args.ClusterName, name),
+		},
+	}
+
+	if args.KmsKeyArn != nil {
+		fileSystemArgs.KmsKeyId = args.KmsKeyArn
+	}
+
+	fileSystem, err := efs.NewFileSystem(ctx, fmt.Sprintf("%s-file-system", name), fileSystemArgs, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	mountTargetSecurityGroup, err := ec2.NewSecurityGroup(ctx, fmt.Sprintf("%s-mount-target-security-group", name), &ec2.SecurityGroupArgs{
+		Description: pulumi.StringPtr("nfs from the nodes to the efs mount targets"),
+		VpcId:       args.VpcId,
+		Ingress: ec2.SecurityGroupIngressArray{
+			ec2.SecurityGroupIngressArgs{
+				Description:    pulumi.StringPtr("nfs from the nodes"),
+				Protocol:       pulumi.String("tcp"),
+				FromPort:       pulumi.Int(2049),
+				ToPort:         pulumi.Int(2049),
+				SecurityGroups: pulumi.StringArray{args.NodeSecurityGroupId},
+			},
+		},
+		Tags: pulumi.StringMap{
+			"Name": pulumi.Sprintf("%s-efs", name),
+		},
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var mountTargets []pulumi.Resource
+
+	for i, subnet := range args.Subnets {
+		// keyed by subnet so a removed subnet doesn't shift the others, by position when the id is unknown at preview
+		mountTargetName := fmt.Sprintf("%s-mount-target-%v", name, i)
+		if subnet.KnownId != "" {
+			mountTargetName = fmt.Sprintf("%s-mount-target-%s", name, subnet.KnownId)
+		}
+
+		mountTarget, err := efs.NewMountTarget(ctx, mountTargetName, &efs.MountTargetArgs{
+			FileSystemId:   fileSystem.ID(),
+			SubnetId:       subnet.Id,
+			SecurityGroups: pulumi.StringArray{mountTargetSecurityGroup.ID()},
+		}, pulumi.Parent(fileSystem))
+
+		if err != nil {
+			return nil, err
+		}
+
+		mountTargets = append(mountTargets, mountTarget)
+	}
+
+	// controller and node service accounts, both efs-csi-*
+	driver, err := NewManagedAddon(ctx, name, &ManagedAddonArgs{
+		ClusterName:            args.ClusterName,
+		AddonName:              "aws-efs-csi-driver",
+		KubernetesVersion:      args.KubernetesVersion,
+		ServiceAccount:         "kube-system:efs-csi-*",
+		IssuerUrlWithoutPrefix: args.IssuerUrlWithoutPrefix,
+		ManagedPolicyArns:      []string{"arn:aws:iam::aws:policy/service-role/AmazonEFSCSIDriverPolicy"},
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	// an access point per PersistentVolume, each one rooted in its own directory
+	// https://github.com/kubernetes-sigs/aws-efs-csi-driver/blob/master/docs/README.md#storage-class-parameters-for-dynamic-provisioning
+	_, err = storagev1.NewStorageClass(ctx, fmt.Sprintf("%s-storage-class", name), &storagev1.StorageClassArgs{
+		Metadata: metav1.ObjectMetaArgs{
+			Name: pulumi.StringPtr("efs"),
+		},
+		Provisioner: pulumi.String("efs.csi.aws.com"),
+		Parameters: pulumi.StringMap{
+			"provisioningMode": pulumi.String("efs-ap"),
+			"fileSystemId":     fileSystem.ID(),
+			"directoryPerms":   pulumi.String("700"),
+			"basePath":         pulumi.String("/dynamic"),
+		},
+		ReclaimPolicy: pulumi.StringPtr(ReclaimPolicyDelete),
+	}, pulumi.Parent(componentResource), pulumi.DependsOn(append(mountTargets, driver)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	componentResource.FileSystem = fileSystem
+
+	ctx.Export("EfsFileSystemId", fileSystem.ID())
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
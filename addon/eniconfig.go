@@ -0,0 +1,45 @@
+package addon
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apiextensions"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// newEniConfigs creates one ENIConfig per zone, named after the zone so the CNI finds it through EniConfigLabel.
+// Nodes must be launched after them, existing nodes keep their pods on the node subnet until replaced
+// https://docs.aws.amazon.com/eks/latest/userguide/cni-custom-network.html
+func newEniConfigs(ctx *pulumi.Context, name string, podSubnets map[string]string, securityGroupIds pulumi.StringArrayInput, vpcCniAddon *eks.Addon, parent pulumi.Resource) error {
+	zones := make([]string, 0, len(podSubnets))
+	for zone := range podSubnets {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+
+	for _, zone := range zones {
+		_, err := apiextensions.NewCustomResource(ctx, fmt.Sprintf("%s-eniconfig-%s", name, zone), &apiextensions.CustomResourceArgs{
+			ApiVersion: pulumi.String("crd.k8s.amazonaws.com/v1alpha1"),
+			Kind:       pulumi.String("ENIConfig"),
+			Metadata: metav1.ObjectMetaArgs{
+				Name: pulumi.StringPtr(zone),
+			},
+			OtherFields: kubernetes.UntypedArgs{
+				"spec": pulumi.Map{
+					"subnet":         pulumi.String(podSubnets[zone]),
+					"securityGroups": securityGroupIds,
+				},
+			},
+		}, pulumi.Parent(parent), pulumi.DependsOn([]pulumi.Resource{vpcCniAddon}))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+// An add-on is software that provides supporting operational capabilities to Kubernetes applications
+// And is "COMPLETELY INSTALED BY AWS"
+package addon
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// KubeProxyArgs.Mode values
+const (
+	KubeProxyModeIptables = "iptables"
+	KubeProxyModeIpvs     = "ipvs"
+)
+
+type KubeProxy struct {
+	pulumi.ResourceState
+}
+
+type KubeProxyArgs struct {
+	ClusterName pulumi.StringInput `json:"-"`
+	// Cluster version the addon version is resolved for, nil lets EKS pick
+	KubernetesVersion pulumi.StringInput `json:"-"`
+	// KubeProxyModeIptables (default) or KubeProxyModeIpvs
+	Mode string `json:"mode"`
+	// ipvs only, eg. "rr" or "lc"
+	IpvsScheduler string `json:"ipvsScheduler"`
+}
+
+func (args *KubeProxyArgs) validate() error {
+	switch args.Mode {
+	case "", KubeProxyModeIptables:
+		if args.IpvsScheduler != "" {
+			return fmt.Errorf("IpvsScheduler needs mode %s", KubeProxyModeIpvs)
+		}
+	case KubeProxyModeIpvs:
+	default:
+		return fmt.Errorf("Mode %q must be %s or %s", args.Mode, KubeProxyModeIptables, KubeProxyModeIpvs)
+	}
+	return nil
+}
+
+// configurationValues follows aws eks describe-addon-configuration --addon-name kube-proxy
+func (args *KubeProxyArgs) configurationValues() (string, error) {
+	values := map[string]interface{}{}
+
+	if args.Mode != "" {
+		values["mode"] = args.Mode
+	}
+	if args.IpvsScheduler != "" {
+		values["ipvs"] = map[string]string{"scheduler": args.IpvsScheduler}
+	}
+
+	rendered, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("kube-proxy configuration values: %w", err)
+	}
+	return string(rendered), nil
+}
+
+func NewKubeProxy(ctx *pulumi.Context, name string, args *KubeProxyArgs, opts ...pulumi.ResourceOption) (*KubeProxy, error) {
+	componentResource := &KubeProxy{}
+
+	if args == nil {
+		args = &KubeProxyArgs{}
+	}
+
+	err := args.validate()
+	if err != nil {
+		return nil, fmt.Errorf("kube-proxy %q: %w", name, err)
+	}
+
+	configurationValues, err := args.configurationValues()
+	if err != nil {
+		return nil, fmt.Errorf("kube-proxy %q: %w", name, err)
+	}
+
+	// <package>:<module>:<type>
+	err = ctx.RegisterComponentResource("my-own-cluster:addon:KubeProxy", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = NewManagedAddon(ctx, name, &ManagedAddonArgs{
+		ClusterName:         args.ClusterName,
+		AddonName:           "kube-proxy",
+		KubernetesVersion:   args.KubernetesVersion,
+		ConfigurationValues: pulumi.String(configurationValues),
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
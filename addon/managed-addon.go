@@ -0,0 +1,197 @@
+// An add-on is software that provides supporting operational capabilities to Kubernetes applications
+// And is "COMPLETELY INSTALED BY AWS"
+package addon
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"k8s-cluster-own/cluster"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ManagedAddonArgs.ResolveConflictsOnCreate/OnUpdate values
+const (
+	ResolveConflictsNone      = "NONE"
+	ResolveConflictsOverwrite = "OVERWRITE"
+	// update only, keeps the fields changed outside the addon
+	ResolveConflictsPreserve = "PRESERVE"
+)
+
+// ManagedAddon is an EKS addon with its version resolved for the cluster and, optionally, its IRSA or Pod Identity role.
+// The resolved version is exported as <addon>-version so previews show upgrades
+type ManagedAddon struct {
+	pulumi.ResourceState
+	Addon *eks.Addon
+	// nil without ServiceAccount
+	Role *iam.Role
+	// nil unless IdentityMode is cluster.IdentityModePodIdentity
+	Association *eks.PodIdentityAssociation
+	Version     pulumi.StringOutput
+}
+
+type ManagedAddonArgs struct {
+	ClusterName pulumi.StringInput
+	// eg. "aws-ebs-csi-driver"
+	AddonName string
+	// The version is resolved for it, the cluster Version output so addons upgrade after the control plane.
+	// nil without Version lets EKS pick
+	KubernetesVersion pulumi.StringInput
+	// Pin, eg. "v1.15.1-eksbuild.1", wins over the resolved version
+	Version string
+	// Latest compatible version instead of the EKS default
+	MostRecent          bool
+	ConfigurationValues pulumi.StringInput
+	// Default to ResolveConflictsOverwrite, adopting what EKS installed with the cluster
+	ResolveConflictsOnCreate string
+	ResolveConflictsOnUpdate string
+	// Role for "namespace:serviceaccount" (wildcards allowed with IRSA, eg. "kube-system:efs-csi-*")
+	ServiceAccount         string
+	IssuerUrlWithoutPrefix pulumi.StringInput
+	ManagedPolicyArns      []string
+	// name -> policy document
+	InlinePolicies map[string]pulumi.StringInput
+	// cluster.IdentityModeIrsa (default) annotates the service account through the addon,
+	// cluster.IdentityModePodIdentity associates the role instead
+	IdentityMode string
+
+	// only for EbsController, the names its addon and role had before being moved here, adopted instead of replaced
+	legacyAddonName string
+	legacyRoleName  string
+	legacyParent    pulumi.Resource
+}
+
+func (args *ManagedAddonArgs) withDefaults() {
+	if args.ResolveConflictsOnCreate == "" {
+		args.ResolveConflictsOnCreate = ResolveConflictsOverwrite
+	}
+	if args.ResolveConflictsOnUpdate == "" {
+		args.ResolveConflictsOnUpdate = ResolveConflictsOverwrite
+	}
+}
+
+func (args *ManagedAddonArgs) validate() error {
+	if args.AddonName == "" {
+		return fmt.Errorf("AddonName is required")
+	}
+	if args.ResolveConflictsOnCreate != ResolveConflictsNone && args.ResolveConflictsOnCreate != ResolveConflictsOverwrite {
+		return fmt.Errorf("ResolveConflictsOnCreate %q must be %s or %s", args.ResolveConflictsOnCreate, ResolveConflictsNone, ResolveConflictsOverwrite)
+	}
+	if !slices.Contains([]string{ResolveConflictsNone, ResolveConflictsOverwrite, ResolveConflictsPreserve}, args.ResolveConflictsOnUpdate) {
+		return fmt.Errorf("ResolveConflictsOnUpdate %q must be %s, %s or %s", args.ResolveConflictsOnUpdate, ResolveConflictsNone, ResolveConflictsOverwrite, ResolveConflictsPreserve)
+	}
+	if args.Version != "" && args.MostRecent {
+		return fmt.Errorf("Version %q and MostRecent can't be used together", args.Version)
+	}
+	return cluster.ValidateIdentityMode(args.IdentityMode)
+}
+
+func NewManagedAddon(ctx *pulumi.Context, name string, args *ManagedAddonArgs, opts ...pulumi.ResourceOption) (*ManagedAddon, error) {
+	componentResource := &ManagedAddon{}
+
+	if args == nil {
+		args = &ManagedAddonArgs{}
+	}
+
+	args.withDefaults()
+	err := args.validate()
+	if err != nil {
+		return nil, fmt.Errorf("managed addon %q: %w", name, err)
+	}
+
+	// <package>:<module>:<type>
+	err = ctx.RegisterComponentResource("my-own-cluster:addon:ManagedAddon", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	addonArgs := &eks.AddonArgs{
+		ClusterName:              args.ClusterName,
+		AddonName:                pulumi.String(args.AddonName),
+		ResolveConflictsOnCreate: pulumi.StringPtr(args.ResolveConflictsOnCreate),
+		ResolveConflictsOnUpdate: pulumi.StringPtr(args.ResolveConflictsOnUpdate),
+	}
+
+	if args.Version != "" {
+		addonArgs.AddonVersion = pulumi.StringPtr(args.Version)
+	} else if args.KubernetesVersion != nil {
+		addonArgs.AddonVersion = compatibleVersion(ctx, args.AddonName, args.KubernetesVersion, args.MostRecent).ToStringPtrOutput()
+	}
+
+	if args.ConfigurationValues != nil {
+		addonArgs.ConfigurationValues = args.ConfigurationValues
+	}
+
+	var addonDependencies []pulumi.Resource
+	if args.ServiceAccount != "" {
+		var inlinePolicies iam.RoleInlinePolicyArray
+		policyNames := make([]string, 0, len(args.InlinePolicies))
+		for policyName := range args.InlinePolicies {
+			policyNames = append(policyNames, policyName)
+		}
+		sort.Strings(policyNames)
+		for _, policyName := range policyNames {
+			inlinePolicies = append(inlinePolicies, iam.RoleInlinePolicyArgs{
+				Name:   pulumi.StringPtr(policyName),
+				Policy: args.InlinePolicies[policyName].ToStringOutput().ToStringPtrOutput(),
+			})
+		}
+
+		serviceAccountRole, err := cluster.NewServiceAccountRole(ctx, fmt.Sprintf("%s-role", name), &cluster.ServiceAccountRoleArgs{
+			IdentityMode:           args.IdentityMode,
+			ClusterName:            args.ClusterName,
+			IssuerUrlWithoutPrefix: args.IssuerUrlWithoutPrefix,
+			ServiceAccount:         args.ServiceAccount,
+			ManagedPolicyArns:      pulumi.ToStringArray(args.ManagedPolicyArns),
+			InlinePolicies:         inlinePolicies,
+		}, legacyOptions(componentResource, args.legacyRoleName, args.legacyParent)...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// with Pod Identity the addon must not annotate the service account
+		if args.IdentityMode != cluster.IdentityModePodIdentity {
+			addonArgs.ServiceAccountRoleArn = serviceAccountRole.Role.Arn
+		}
+		componentResource.Role = serviceAccountRole.Role
+		componentResource.Association = serviceAccountRole.Association
+		addonDependencies = serviceAccountRole.Ready()
+	}
+
+	// the addon pods must not start before their credentials exist
+	addonOpts := append(legacyOptions(componentResource, args.legacyAddonName, args.legacyParent), pulumi.DependsOn(addonDependencies))
+	addon, err := eks.NewAddon(ctx, fmt.Sprintf("%s-addon", name), addonArgs, addonOpts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	componentResource.Addon = addon
+	componentResource.Version = addon.AddonVersion
+
+	// keyed on the resource name, two addons of the same type must not overwrite each other
+	ctx.Export(fmt.Sprintf("%s-version", name), addon.AddonVersion)
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{
+		"version": addon.AddonVersion,
+	})
+
+	return componentResource, nil
+}
+
+// legacyOptions parents the resource to the ManagedAddon, aliased to the name it had under its old parent
+func legacyOptions(parent pulumi.Resource, legacyName string, legacyParent pulumi.Resource) []pulumi.ResourceOption {
+	opts := []pulumi.ResourceOption{pulumi.Parent(parent)}
+	if legacyName != "" {
+		opts = append(opts, pulumi.Aliases([]pulumi.Alias{{
+			Name:   pulumi.String(legacyName),
+			Parent: legacyParent,
+		}}))
+	}
+	return opts
+}
@@ -0,0 +1,46 @@
+// An add-on is software that provides supporting operational capabilities to Kubernetes applications
+// And is "COMPLETELY INSTALED BY AWS"
+package addon
+
+import (
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// PodIdentityAgent is the node DaemonSet handing out EKS Pod Identity credentials, it needs no role itself
+type PodIdentityAgent struct {
+	pulumi.ResourceState
+}
+
+type PodIdentityAgentArgs struct {
+	ClusterName pulumi.StringInput
+	// Cluster version the addon version is resolved for, nil lets EKS pick
+	KubernetesVersion pulumi.StringInput
+}
+
+func NewPodIdentityAgent(ctx *pulumi.Context, name string, args *PodIdentityAgentArgs, opts ...pulumi.ResourceOption) (*PodIdentityAgent, error) {
+	componentResource := &PodIdentityAgent{}
+
+	if args == nil {
+		args = &PodIdentityAgentArgs{}
+	}
+
+	// <package>:<module>:<type>
+	err := ctx.RegisterComponentResource("my-own-cluster:addon:PodIdentityAgent", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = NewManagedAddon(ctx, name, &ManagedAddonArgs{
+		ClusterName:       args.ClusterName,
+		AddonName:         "eks-pod-identity-agent",
+		KubernetesVersion: args.KubernetesVersion,
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
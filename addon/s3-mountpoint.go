@@ -0,0 +1,279 @@
+// An add-on is software that provides supporting operational capabilities to Kubernetes applications
+// And is "COMPLETELY INSTALED BY AWS"
+package addon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
+	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// S3MountpointBucket.Mode values
+const (
+	S3MountRead      = "read"
+	S3MountReadWrite = "readwrite"
+)
+
+// Mountpoint ignores the size, PVs need one
+const s3VolumeSize = "1200Gi"
+
+// S3MountpointBucket is a bucket (or a prefix of it) the driver may mount.
+// With Namespace set a static PersistentVolume/PersistentVolumeClaim pair named Name is created there
+type S3MountpointBucket struct {
+	// Kubernetes name of the volume, defaults to the bucket name
+	Name       string `json:"name"`
+	BucketName string `json:"bucketName"`
+	// Without trailing slash, empty mounts the whole bucket
+	Prefix    string `json:"prefix"`
+	Mode      string `json:"mode"`
+	Namespace string `json:"namespace"`
+}
+
+type S3Mountpoint struct {
+	pulumi.ResourceState
+}
+
+type S3MountpointArgs struct {
+	ClusterName            pulumi.StringInput
+	IssuerUrlWithoutPrefix pulumi.StringInput
+	// Cluster version the addon version is resolved for, nil lets EKS pick
+	KubernetesVersion pulumi.StringInput
+	// The IRSA policy only covers these buckets and prefixes
+	Buckets []S3MountpointBucket
+}
+
+func (b S3MountpointBucket) withDefaults() S3MountpointBucket {
+	if b.Name == "" {
+		b.Name = b.BucketName
+	}
+	if b.Mode == "" {
+		b.Mode = S3MountRead
+	}
+	b.Prefix = strings.Trim(b.Prefix, "/")
+	return b
+}
+
+func (b S3MountpointBucket) validate() error {
+	if b.BucketName == "" {
+		return fmt.Errorf("bucket %q: bucketName is required", b.Name)
+	}
+	if b.Mode != S3MountRead && b.Mode != S3MountReadWrite {
+		return fmt.Errorf("bucket %q: mode %q must be %s or %s", b.Name, b.Mode, S3MountRead, S3MountReadWrite)
+	}
+	return nil
+}
+
+// BucketArn is what the S3 gateway endpoint policy has to let the driver reach
+func (b S3MountpointBucket) BucketArn(partition string) string {
+	return fmt.Sprintf("arn:%s:s3:::%s", partition, b.BucketName)
+}
+
+// objectArn covers the prefix only, or every object without one
+func (b S3MountpointBucket) objectArn(partition string) string {
+	if b.Prefix == "" {
+		return fmt.Sprintf("arn:%s:s3:::%s/*", partition, b.BucketName)
+	}
+	return fmt.Sprintf("arn:%s:s3:::%s/%s/*", partition, b.BucketName, b.Prefix)
+}
+
+// s3MountpointPolicy lists each bucket (limited to its prefix) and reads, or writes, its objects
+// https://github.com/awslabs/mountpoint-s3/blob/main/doc/CONFIGURATION.md#iam-permissions
+func s3MountpointPolicy(partition string, buckets []S3MountpointBucket) (string, error) {
+	type statement struct {
+		Effect    string                       `json:"Effect"`
+		Action    []string                     `json:"Action"`
+		Resource  []string                     `json:"Resource"`
+		Condition map[string]map[string]string `json:"Condition,omitempty"`
+	}
+
+	var statements []statement
+	for _, bucket := range buckets {
+		list := statement{
+			Effect:   "Allow",
+			Action:   []string{"s3:ListBucket"},
+			Resource: []string{bucket.BucketArn(partition)},
+		}
+		if bucket.Prefix != "" {
+			list.Condition = map[string]map[string]string{
+				"StringLike": {"s3:prefix": bucket.Prefix + "/*"},
+			}
+		}
+
+		actions := []string{"s3:GetObject"}
+		if bucket.Mode == S3MountReadWrite {
+			actions = append(actions, "s3:PutObject", "s3:AbortMultipartUpload", "s3:DeleteObject")
+		}
+
+		statements = append(statements, list, statement{
+			Effect:   "Allow",
+			Action:   actions,
+			Resource: []string{bucket.objectArn(partition)},
+		})
+	}
+
+	policy, err := json.MarshalIndent(map[string]interface{}{
+		"Version":   "2012-10-17",
+		"Statement": statements,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(policy), nil
+}
+
+func NewS3Mountpoint(ctx *pulumi.Context, name string, args *S3MountpointArgs, opts ...pulumi.ResourceOption) (*S3Mountpoint, error) {
+	componentResource := &S3Mountpoint{}
+
+	if args == nil {
+		args = &S3MountpointArgs{}
+	}
+
+	if len(args.Buckets) == 0 {
+		return nil, fmt.Errorf("s3 mountpoint %q: at least one bucket is required", name)
+	}
+
+	var buckets []S3MountpointBucket
+	seen := map[string]bool{}
+	for _, bucket := range args.Buckets {
+		bucket = bucket.withDefaults()
+		if err := bucket.validate(); err != nil {
+			return nil, fmt.Errorf("s3 mountpoint %q: %w", name, err)
+		}
+		if seen[bucket.Name] {
+			return nil, fmt.Errorf("s3 mountpoint %q: volume %q is declared more than once", name, bucket.Name)
+		}
+		seen[bucket.Name] = true
+		buckets = append(buckets, bucket)
+	}
+
+	// aws-cn and aws-us-gov buckets have their own ARN prefix
+	partition, err := aws.GetPartition(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketsPolicy, err := s3MountpointPolicy(partition.Partition, buckets)
+	if err != nil {
+		return nil, fmt.Errorf("s3 mountpoint %q: %w", name, err)
+	}
+
+	// <package>:<module>:<type>
+	err = ctx.RegisterComponentResource("k8s-nodes:addon:S3Mountpoint", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := NewManagedAddon(ctx, name, &ManagedAddonArgs{
+		ClusterName:            args.ClusterName,
+		AddonName:              "aws-mountpoint-s3-csi-driver",
+		KubernetesVersion:      args.KubernetesVersion,
+		ServiceAccount:         "kube-system:s3-csi-driver-sa",
+		IssuerUrlWithoutPrefix: args.IssuerUrlWithoutPrefix,
+		InlinePolicies: map[string]pulumi.StringInput{
+			"mountpoint-s3-buckets": pulumi.String(bucketsPolicy),
+		},
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bucket := range buckets {
+		if bucket.Namespace == "" {
+			continue
+		}
+
+		_, _, err = NewS3BucketVolume(ctx, fmt.Sprintf("%s-%s", name, bucket.Name), bucket,
+			pulumi.Parent(componentResource), pulumi.DependsOn([]pulumi.Resource{driver}))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
+
+// NewS3BucketVolume binds a static PersistentVolume for the bucket to a claim named bucket.Name in bucket.Namespace.
+// Mountpoint has no dynamic provisioning, pods mount the claim
+func NewS3BucketVolume(ctx *pulumi.Context, name string, bucket S3MountpointBucket, opts ...pulumi.ResourceOption) (*corev1.PersistentVolume, *corev1.PersistentVolumeClaim, error) {
+	bucket = bucket.withDefaults()
+	if err := bucket.validate(); err != nil {
+		return nil, nil, err
+	}
+	if bucket.Namespace == "" {
+		return nil, nil, fmt.Errorf("bucket %q: namespace is required for a volume", bucket.Name)
+	}
+
+	accessMode := "ReadOnlyMany"
+	mountOptions := []string{"read-only"}
+	if bucket.Mode == S3MountReadWrite {
+		accessMode = "ReadWriteMany"
+		mountOptions = []string{"allow-delete"}
+	}
+	if bucket.Prefix != "" {
+		mountOptions = append(mountOptions, fmt.Sprintf("prefix %s/", bucket.Prefix))
+	}
+
+	// cluster scoped, the namespace keeps names apart
+	volumeName := fmt.Sprintf("s3-%s-%s", bucket.Namespace, bucket.Name)
+
+	volume, err := corev1.NewPersistentVolume(ctx, fmt.Sprintf("%s-volume", name), &corev1.PersistentVolumeArgs{
+		Metadata: metav1.ObjectMetaArgs{
+			Name: pulumi.StringPtr(volumeName),
+		},
+		Spec: corev1.PersistentVolumeSpecArgs{
+			Capacity: pulumi.StringMap{
+				"storage": pulumi.String(s3VolumeSize),
+			},
+			AccessModes:      pulumi.ToStringArray([]string{accessMode}),
+			MountOptions:     pulumi.ToStringArray(mountOptions),
+			StorageClassName: pulumi.StringPtr(""),
+			ClaimRef: corev1.ObjectReferenceArgs{
+				Namespace: pulumi.StringPtr(bucket.Namespace),
+				Name:      pulumi.StringPtr(bucket.Name),
+			},
+			Csi: corev1.CSIPersistentVolumeSourceArgs{
+				Driver:       pulumi.String("s3.csi.aws.com"),
+				VolumeHandle: pulumi.String(volumeName),
+				VolumeAttributes: pulumi.StringMap{
+					"bucketName": pulumi.String(bucket.BucketName),
+				},
+			},
+		},
+	}, opts...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claim, err := corev1.NewPersistentVolumeClaim(ctx, fmt.Sprintf("%s-claim", name), &corev1.PersistentVolumeClaimArgs{
+		Metadata: metav1.ObjectMetaArgs{
+			Name:      pulumi.StringPtr(bucket.Name),
+			Namespace: pulumi.StringPtr(bucket.Namespace),
+		},
+		Spec: corev1.PersistentVolumeClaimSpecArgs{
+			AccessModes:      pulumi.ToStringArray([]string{accessMode}),
+			StorageClassName: pulumi.StringPtr(""),
+			VolumeName:       volume.Metadata.Name().Elem(),
+			Resources: corev1.ResourceRequirementsArgs{
+				Requests: pulumi.StringMap{
+					"storage": pulumi.String(s3VolumeSize),
+				},
+			},
+		},
+	}, opts...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return volume, claim, nil
+}
@@ -0,0 +1,28 @@
+package addon
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// compatibleVersion resolves the version EKS publishes for the addon on that kubernetes version,
+// the default one or the most recent. Reading the cluster Version output keeps the addon update after the control plane upgrade
+func compatibleVersion(ctx *pulumi.Context, addonName string, kubernetesVersion pulumi.StringInput, mostRecent bool) pulumi.StringOutput {
+	return eks.GetAddonVersionOutput(ctx, eks.GetAddonVersionOutputArgs{
+		AddonName:         pulumi.String(addonName),
+		KubernetesVersion: kubernetesVersion,
+		MostRecent:        pulumi.BoolPtr(mostRecent),
+	}).Version()
+}
+
+// olderThan compares the minor of a pinned version, eg. "v1.13.4-eksbuild.1", unparseable pins are not older
+func olderThan(version string, major int, minor int) bool {
+	var versionMajor, versionMinor int
+	_, err := fmt.Sscanf(version, "v%d.%d", &versionMajor, &versionMinor)
+	if err != nil {
+		return false
+	}
+	return versionMajor < major || (versionMajor == major && versionMinor < minor)
+}
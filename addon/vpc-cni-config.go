@@ -0,0 +1,122 @@
+package addon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"k8s-cluster-own/cluster"
+)
+
+// EniConfigLabel is the node label naming the ENIConfig, ENIConfigs are named after zones
+const EniConfigLabel = "topology.kubernetes.io/zone"
+
+// VpcCniConfig is the subset of the vpc-cni addon configuration schema we use
+// (aws eks describe-addon-configuration --addon-name vpc-cni), rendered into ConfigurationValues
+type VpcCniConfig struct {
+	// Kubernetes NetworkPolicy through the node agent, needs vpc-cni v1.14+
+	EnableNetworkPolicy bool `json:"enableNetworkPolicy"`
+	// /28 prefixes per ENI slot instead of single IPs, a lot more pods per node (Nitro only)
+	PrefixDelegation bool `json:"prefixDelegation"`
+	// Free IPs (or prefixes) kept attached to each node, 0 leaves the CNI default
+	WarmIpTarget     int `json:"warmIpTarget"`
+	MinimumIpTarget  int `json:"minimumIpTarget"`
+	WarmPrefixTarget int `json:"warmPrefixTarget"`
+	// Pods get IPs from ENIConfig subnets instead of the node subnet
+	CustomNetworking bool `json:"customNetworking"`
+	// Pod subnets by availability zone for custom networking, eg. on a secondary 100.64.0.0/10 CIDR.
+	// One ENIConfig per zone, named after it and picked by the node zone label
+	PodSubnets map[string]string `json:"podSubnets"`
+	// Branch ENIs for security groups per pod
+	PodEni bool `json:"podEni"`
+}
+
+func (c *VpcCniConfig) validate(ipFamily string) error {
+	if c.WarmIpTarget < 0 || c.MinimumIpTarget < 0 || c.WarmPrefixTarget < 0 {
+		return errors.New("warmIpTarget, minimumIpTarget and warmPrefixTarget can't be negative")
+	}
+	if c.WarmPrefixTarget > 0 && !c.PrefixDelegation {
+		return errors.New("warmPrefixTarget needs prefixDelegation")
+	}
+	if c.MinimumIpTarget > 0 && c.WarmIpTarget == 0 && !c.PrefixDelegation {
+		// without WARM_IP_TARGET the CNI keeps a whole ENI warm and ignores MINIMUM_IP_TARGET
+		return errors.New("minimumIpTarget needs warmIpTarget")
+	}
+
+	if c.CustomNetworking && len(c.PodSubnets) == 0 {
+		return errors.New("customNetworking needs podSubnets, one per availability zone with nodes")
+	}
+	if !c.CustomNetworking && len(c.PodSubnets) > 0 {
+		return errors.New("podSubnets are only used with customNetworking")
+	}
+	for zone, subnetId := range c.PodSubnets {
+		if zone == "" || subnetId == "" {
+			return fmt.Errorf("podSubnets entry %q: %q needs a zone and a subnet id", zone, subnetId)
+		}
+	}
+
+	if ipFamily == cluster.IpFamilyIpv6 {
+		if !c.PrefixDelegation {
+			return errors.New("ipv6 clusters need prefixDelegation")
+		}
+		if c.CustomNetworking {
+			return errors.New("customNetworking isn't supported on ipv6 clusters")
+		}
+	}
+
+	return nil
+}
+
+// configurationValues renders the addon JSON, env values are strings in the schema
+func (c *VpcCniConfig) configurationValues() (string, error) {
+	env := map[string]string{}
+
+	if c.PrefixDelegation {
+		env["ENABLE_PREFIX_DELEGATION"] = "true"
+	}
+	if c.WarmIpTarget > 0 {
+		env["WARM_IP_TARGET"] = strconv.Itoa(c.WarmIpTarget)
+	}
+	if c.MinimumIpTarget > 0 {
+		env["MINIMUM_IP_TARGET"] = strconv.Itoa(c.MinimumIpTarget)
+	}
+	if c.WarmPrefixTarget > 0 {
+		env["WARM_PREFIX_TARGET"] = strconv.Itoa(c.WarmPrefixTarget)
+	}
+	if c.CustomNetworking {
+		env["AWS_VPC_K8S_CNI_CUSTOM_NETWORK_CFG"] = "true"
+		env["ENI_CONFIG_LABEL_DEF"] = EniConfigLabel
+	}
+	if c.PodEni {
+		env["ENABLE_POD_ENI"] = "true"
+	}
+
+	values := map[string]interface{}{
+		"enableNetworkPolicy": strconv.FormatBool(c.EnableNetworkPolicy),
+	}
+	if len(env) > 0 {
+		values["env"] = env
+	}
+	// kubelet probes reach pods with their own security group through the branch ENI
+	// https://docs.aws.amazon.com/eks/latest/userguide/security-groups-for-pods.html
+	if c.PodEni {
+		values["init"] = map[string]interface{}{
+			"env": map[string]string{"DISABLE_TCP_EARLY_DEMUX": "true"},
+		}
+	}
+
+	rendered, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("vpc-cni configuration values: %w", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(rendered, &decoded); err != nil {
+		return "", fmt.Errorf("vpc-cni configuration values: %w", err)
+	}
+	if err := vpcCniSchema.validate("", decoded); err != nil {
+		return "", fmt.Errorf("vpc-cni configuration values: %w", err)
+	}
+	return string(rendered), nil
+}
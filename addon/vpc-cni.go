@@ -5,12 +5,9 @@ package addon
 import (
 	"fmt"
 
-	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
-	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
-	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
-	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"k8s-cluster-own/cluster"
+
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
-	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 )
 
 type VpcCni struct {
@@ -20,6 +17,18 @@ type VpcCni struct {
 type VpcCniArgs struct {
 	IssuerUrlWithoutPrefix pulumi.StringInput
 	ClusterName            pulumi.StringInput
+	// Cluster version the addon version is resolved for, nil lets EKS pick
+	KubernetesVersion pulumi.StringInput
+	// Pins the addon version instead
+	Version string
+	// cluster.IdentityModeIrsa (default) or cluster.IdentityModePodIdentity
+	IdentityMode string
+	// cluster.IpFamilyIpv6 swaps AmazonEKS_CNI_Policy for the ipv6 policy
+	IpFamily string
+	// Rendered into the addon ConfigurationValues
+	Config VpcCniConfig
+	// Security groups of the pod ENIs with custom networking, usually the node security group
+	PodSecurityGroupIds pulumi.StringArrayInput
 }
 
 func NewVpcCni(ctx *pulumi.Context, name string, args *VpcCniArgs, opts ...pulumi.ResourceOption) (*VpcCni, error) {
@@ -29,69 +38,57 @@ func NewVpcCni(ctx *pulumi.Context, name string, args *VpcCniArgs, opts ...pulum
 		args = &VpcCniArgs{}
 	}
 
-	cfg := config.New(ctx, "")
-	account := cfg.GetSecret("account")
-
-	// <package>:<module>:<type>
-	err := ctx.RegisterComponentResource("my-own-cluster:addon:VpcCni", name, componentResource, opts...)
+	err := args.Config.validate(args.IpFamily)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("vpc cni %q: %w", name, err)
+	}
+	if args.Config.CustomNetworking && args.PodSecurityGroupIds == nil {
+		return nil, fmt.Errorf("vpc cni %q: customNetworking needs PodSecurityGroupIds", name)
+	}
+	if args.Config.EnableNetworkPolicy && olderThan(args.Version, 1, 14) {
+		return nil, fmt.Errorf("vpc cni %q: enableNetworkPolicy needs vpc-cni v1.14+, Version is %s", name, args.Version)
 	}
 
-	trustedpolicy := pulumi.Sprintf(`{
-				    "Version": "2012-10-17",
-				    "Statement": [
-				        {
-				            "Effect": "Allow",
-				            "Principal": {
-				                "Federated": "arn:aws:iam::%s:oidc-provider/%s"
-				            },
-				            "Action": "sts:AssumeRoleWithWebIdentity",
-				            "Condition": {
-				                "StringEquals": {
-				                    "%s:aud": "sts.amazonaws.com",
-				                    "%s:sub": "system:serviceaccount:kube-system:aws-node"
-				                }
-				            }
-				        }
-				    ]
-				}`, account, args.IssuerUrlWithoutPrefix, args.IssuerUrlWithoutPrefix, args.IssuerUrlWithoutPrefix)
-	//
-	vpcCniRole, err := iam.NewRole(ctx, fmt.Sprintf("%s-Vpc-cni-role", name), &iam.RoleArgs{
-		AssumeRolePolicy:  trustedpolicy,
-		ManagedPolicyArns: pulumi.ToStringArray([]string{"arn:aws:iam::aws:policy/AmazonEKS_CNI_Policy"}),
-	}, pulumi.Parent(componentResource))
+	configurationValues, err := args.Config.configurationValues()
+	if err != nil {
+		return nil, fmt.Errorf("vpc cni %q: %w", name, err)
+	}
 
+	// <package>:<module>:<type>
+	err = ctx.RegisterComponentResource("my-own-cluster:addon:VpcCni", name, componentResource, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = corev1.NewServiceAccount(ctx, fmt.Sprintf("%s-VpcCNI-addon-ServiceAccount", name), &corev1.ServiceAccountArgs{
-		Metadata: metav1.ObjectMetaArgs{
-			Annotations: pulumi.StringMap{
-				"eks.amazonaws.com/role-arn":               pulumi.Sprintf("%s", vpcCniRole.Arn),
-				"eks.amazonaws.com/sts-regional-endpoints": pulumi.String("true"),
-			},
-			Name:      pulumi.StringPtr("aws-node"),
-			Namespace: pulumi.StringPtr("kube-system"),
-		},
-	}, pulumi.Parent(componentResource))
+	// EKS owns the aws-node service account, the addon annotates it with the role (IRSA)
+	managedAddonArgs := &ManagedAddonArgs{
+		ClusterName:            args.ClusterName,
+		AddonName:              "vpc-cni",
+		KubernetesVersion:      args.KubernetesVersion,
+		Version:                args.Version,
+		ConfigurationValues:    pulumi.String(configurationValues),
+		ServiceAccount:         "kube-system:aws-node",
+		IssuerUrlWithoutPrefix: args.IssuerUrlWithoutPrefix,
+		IdentityMode:           args.IdentityMode,
+		ManagedPolicyArns:      []string{"arn:aws:iam::aws:policy/AmazonEKS_CNI_Policy"},
+	}
 
-	if err != nil {
-		return nil, err
+	if args.IpFamily == cluster.IpFamilyIpv6 {
+		managedAddonArgs.ManagedPolicyArns = nil
+		managedAddonArgs.InlinePolicies = map[string]pulumi.StringInput{
+			"AmazonEKS_CNI_IPv6_Policy": pulumi.String(cluster.CniIpv6PolicyDocument),
+		}
 	}
-	_, err = eks.NewAddon(ctx, fmt.Sprintf("%s-Vpc-cni-AddOn", name), &eks.AddonArgs{
-		AddonName:                pulumi.String("vpc-cni"),
-		AddonVersion:             pulumi.StringPtr("v1.13.4-eksbuild.1"),
-		ClusterName:              args.ClusterName,
-		ResolveConflictsOnUpdate: pulumi.StringPtr("OVERWRITE"),
-		ServiceAccountRoleArn:    vpcCniRole.Arn,
-	}, pulumi.Parent(componentResource))
+
+	managedAddon, err := NewManagedAddon(ctx, name, managedAddonArgs, pulumi.Parent(componentResource))
 
 	if err != nil {
 		return nil, err
 	}
 
+	// the addon installs the ENIConfig CRD
+	err = newEniConfigs(ctx, name, args.Config.PodSubnets, args.PodSecurityGroupIds, managedAddon.Addon, componentResource)
+
 	if err != nil {
 		return nil, err
 	}
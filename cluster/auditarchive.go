@@ -0,0 +1,366 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/kinesis"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/s3"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+)
+
+// AuditArchive ships the control plane audit logs to S3 through Firehose,
+// CloudWatch keeps the short retention and S3/Glacier the long one
+type AuditArchive struct {
+	pulumi.ResourceState
+	Bucket         *s3.BucketV2
+	DeliveryStream *kinesis.FirehoseDeliveryStream
+}
+
+type AuditArchiveArgs struct {
+	// Needs "audit" in PrincipalClusterArgs.LogTypes
+	Cluster *PrincipalCluster
+	// Days before objects move to Glacier, default 30
+	GlacierAfterDays int
+	// Days before objects are deleted, 0 keeps them forever
+	ExpireAfterDays int
+	// GOVERNANCE (default) or COMPLIANCE, objects can't be deleted before ObjectLockDays (default 365)
+	ObjectLockMode string
+	ObjectLockDays int
+	// Only audit events by default, the log group also has the other control plane logs
+	FilterPattern string
+}
+
+const (
+	defaultGlacierAfterDays = 30
+	defaultObjectLockMode   = "GOVERNANCE"
+	defaultObjectLockDays   = 365
+	auditFilterPattern      = `{ $.kind = "Event" }`
+)
+
+func (args AuditArchiveArgs) withDefaults() AuditArchiveArgs {
+	if args.GlacierAfterDays == 0 {
+		args.GlacierAfterDays = defaultGlacierAfterDays
+	}
+	if args.ObjectLockMode == "" {
+		args.ObjectLockMode = defaultObjectLockMode
+	}
+	if args.ObjectLockDays == 0 {
+		args.ObjectLockDays = defaultObjectLockDays
+	}
+	if args.FilterPattern == "" {
+		args.FilterPattern = auditFilterPattern
+	}
+	return args
+}
+
+func (args AuditArchiveArgs) validate() error {
+	if args.Cluster == nil {
+		return errors.New("Cluster is required")
+	}
+	if args.Cluster.LogGroup == nil || !slices.Contains(args.Cluster.logTypes, "audit") {
+		return errors.New(`the cluster must enable the "audit" control plane log type`)
+	}
+	if args.ObjectLockMode != "GOVERNANCE" && args.ObjectLockMode != "COMPLIANCE" {
+		return fmt.Errorf("objectLockMode %q must be GOVERNANCE or COMPLIANCE", args.ObjectLockMode)
+	}
+	if args.GlacierAfterDays < 0 || args.ObjectLockDays < 1 || args.ExpireAfterDays < 0 {
+		return errors.New("glacierAfterDays, objectLockDays and expireAfterDays can't be negative")
+	}
+	if args.ExpireAfterDays != 0 && args.ExpireAfterDays < args.ObjectLockDays {
+		return fmt.Errorf("expireAfterDays %d is shorter than the object lock of %d days", args.ExpireAfterDays, args.ObjectLockDays)
+	}
+	return nil
+}
+
+func NewAuditArchive(ctx *pulumi.Context, name string, args *AuditArchiveArgs, opts ...pulumi.ResourceOption) (*AuditArchive, error) {
+	componentResource := &AuditArchive{}
+
+	if args == nil {
+		args = &AuditArchiveArgs{}
+	}
+
+	archiveArgs := args.withDefaults()
+
+	if err := archiveArgs.validate(); err != nil {
+		return nil, fmt.Errorf("audit archive %q: %w", name, err)
+	}
+
+	cfg := config.New(ctx, "")
+	account := cfg.GetSecret("account")
+	awscfg := config.New(ctx, "aws")
+	region := awscfg.Require("region")
+
+	// aws-cn and aws-us-gov streams have their own ARN prefix
+	partition, err := aws.GetPartition(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// <package>:<module>:<type>
+	err = ctx.RegisterComponentResource("my-own-cluster:cluster:AuditArchive", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	logGroup := archiveArgs.Cluster.LogGroup
+
+	// object lock can only be enabled when the bucket is created
+	bucket, err := s3.NewBucketV2(ctx, fmt.Sprintf("%s-bucket", name), &s3.BucketV2Args{
+		BucketPrefix:      pulumi.StringPtr(fmt.Sprintf("%s-", name)),
+		ObjectLockEnabled: pulumi.BoolPtr(true),
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s3.NewBucketPublicAccessBlock(ctx, fmt.Sprintf("%s-public-access-block", name), &s3.BucketPublicAccessBlockArgs{
+		Bucket:                bucket.ID(),
+		BlockPublicAcls:       pulumi.BoolPtr(true),
+		BlockPublicPolicy:     pulumi.BoolPtr(true),
+		IgnorePublicAcls:      pulumi.BoolPtr(true),
+		RestrictPublicBuckets: pulumi.BoolPtr(true),
+	}, pulumi.Parent(bucket))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s3.NewBucketServerSideEncryptionConfigurationV2(ctx, fmt.Sprintf("%s-encryption", name), &s3.BucketServerSideEncryptionConfigurationV2Args{
+		Bucket: bucket.ID(),
+		Rules: s3.BucketServerSideEncryptionConfigurationV2RuleArray{
+			s3.BucketServerSideEncryptionConfigurationV2RuleArgs{
+				ApplyServerSideEncryptionByDefault: s3.BucketServerSideEncryptionConfigurationV2RuleApplyServerSideEncryptionByDefaultArgs{
+					SseAlgorithm: pulumi.String("AES256"),
+				},
+			},
+		},
+	}, pulumi.Parent(bucket))
+
+	if err != nil {
+		return nil, err
+	}
+
+	versioning, err := s3.NewBucketVersioningV2(ctx, fmt.Sprintf("%s-versioning", name), &s3.BucketVersioningV2Args{
+		Bucket: bucket.ID(),
+		VersioningConfiguration: s3.BucketVersioningV2VersioningConfigurationArgs{
+			Status: pulumi.String("Enabled"),
+		},
+	}, pulumi.Parent(bucket))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s3.NewBucketObjectLockConfigurationV2(ctx, fmt.Sprintf("%s-object-lock", name), &s3.BucketObjectLockConfigurationV2Args{
+		Bucket: bucket.ID(),
+		Rule: s3.BucketObjectLockConfigurationV2RuleArgs{
+			DefaultRetention: s3.BucketObjectLockConfigurationV2RuleDefaultRetentionArgs{
+				Mode: pulumi.StringPtr(archiveArgs.ObjectLockMode),
+				Days: pulumi.IntPtr(archiveArgs.ObjectLockDays),
+			},
+		},
+	}, pulumi.Parent(bucket), pulumi.DependsOn([]pulumi.Resource{versioning}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	lifecycleRule := s3.BucketLifecycleConfigurationV2RuleArgs{
+		Id:     pulumi.String("archive-audit-logs"),
+		Status: pulumi.String("Enabled"),
+		Filter: s3.BucketLifecycleConfigurationV2RuleFilterArgs{
+			Prefix: pulumi.StringPtr("audit/"),
+		},
+		Transitions: s3.BucketLifecycleConfigurationV2RuleTransitionArray{
+			s3.BucketLifecycleConfigurationV2RuleTransitionArgs{
+				Days:         pulumi.IntPtr(archiveArgs.GlacierAfterDays),
+				StorageClass: pulumi.String("GLACIER"),
+			},
+		},
+		// versioning keeps overwritten and deleted objects, they go once the lock is over
+		NoncurrentVersionExpiration: s3.BucketLifecycleConfigurationV2RuleNoncurrentVersionExpirationArgs{
+			NoncurrentDays: pulumi.IntPtr(archiveArgs.ObjectLockDays),
+		},
+	}
+
+	if archiveArgs.ExpireAfterDays > 0 {
+		lifecycleRule.Expiration = s3.BucketLifecycleConfigurationV2RuleExpirationArgs{
+			Days: pulumi.IntPtr(archiveArgs.ExpireAfterDays),
+		}
+	}
+
+	_, err = s3.NewBucketLifecycleConfigurationV2(ctx, fmt.Sprintf("%s-lifecycle", name), &s3.BucketLifecycleConfigurationV2Args{
+		Bucket: bucket.ID(),
+		Rules:  s3.BucketLifecycleConfigurationV2RuleArray{lifecycleRule},
+	}, pulumi.Parent(bucket), pulumi.DependsOn([]pulumi.Resource{versioning}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	// named up front so both roles can be scoped to it
+	streamName := fmt.Sprintf("%s-audit-archive", name)
+	streamArn := pulumi.Sprintf("arn:%s:firehose:%s:%s:deliverystream/%s", partition.Partition, region, account, streamName)
+
+	firehoseRole, err := iam.NewRole(ctx, fmt.Sprintf("%s-firehose-role", name), &iam.RoleArgs{
+		AssumeRolePolicy: pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {
+        "Service": "firehose.amazonaws.com"
+      },
+      "Action": "sts:AssumeRole",
+      "Condition": {
+        "StringEquals": {
+          "sts:ExternalId": "%s"
+        }
+      }
+    }
+  ]
+}`, account),
+		InlinePolicies: iam.RoleInlinePolicyArray{
+			iam.RoleInlinePolicyArgs{
+				Name: pulumi.StringPtr("WriteAuditArchiveBucket"),
+				Policy: pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": [
+        "s3:AbortMultipartUpload",
+        "s3:GetBucketLocation",
+        "s3:ListBucket",
+        "s3:ListBucketMultipartUploads",
+        "s3:GetObject",
+        "s3:PutObject"
+      ],
+      "Resource": [
+        "%s",
+        "%s/*"
+      ]
+    }
+  ]
+}`, bucket.Arn, bucket.Arn),
+			},
+		},
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	// records from CloudWatch Logs subscriptions arrive gzip wrapped, Firehose unwraps them to the plain
+	// log events and compresses the objects once
+	deliveryStream, err := kinesis.NewFirehoseDeliveryStream(ctx, fmt.Sprintf("%s-delivery-stream", name), &kinesis.FirehoseDeliveryStreamArgs{
+		Name:        pulumi.StringPtr(streamName),
+		Destination: pulumi.String("extended_s3"),
+		ExtendedS3Configuration: kinesis.FirehoseDeliveryStreamExtendedS3ConfigurationArgs{
+			RoleArn:           firehoseRole.Arn,
+			BucketArn:         bucket.Arn,
+			CompressionFormat: pulumi.StringPtr("GZIP"),
+			ProcessingConfiguration: kinesis.FirehoseDeliveryStreamExtendedS3ConfigurationProcessingConfigurationArgs{
+				Enabled: pulumi.BoolPtr(true),
+				Processors: kinesis.FirehoseDeliveryStreamExtendedS3ConfigurationProcessingConfigurationProcessorArray{
+					kinesis.FirehoseDeliveryStreamExtendedS3ConfigurationProcessingConfigurationProcessorArgs{
+						Type: pulumi.String("Decompression"),
+						Parameters: kinesis.FirehoseDeliveryStreamExtendedS3ConfigurationProcessingConfigurationProcessorParameterArray{
+							kinesis.FirehoseDeliveryStreamExtendedS3ConfigurationProcessingConfigurationProcessorParameterArgs{
+								ParameterName:  pulumi.String("CompressionFormat"),
+								ParameterValue: pulumi.String("GZIP"),
+							},
+						},
+					},
+					kinesis.FirehoseDeliveryStreamExtendedS3ConfigurationProcessingConfigurationProcessorArgs{
+						Type: pulumi.String("CloudWatchLogProcessing"),
+						Parameters: kinesis.FirehoseDeliveryStreamExtendedS3ConfigurationProcessingConfigurationProcessorParameterArray{
+							kinesis.FirehoseDeliveryStreamExtendedS3ConfigurationProcessingConfigurationProcessorParameterArgs{
+								ParameterName:  pulumi.String("DataMessageExtraction"),
+								ParameterValue: pulumi.String("true"),
+							},
+						},
+					},
+				},
+			},
+			Prefix:            pulumi.StringPtr("audit/year=!{timestamp:yyyy}/month=!{timestamp:MM}/day=!{timestamp:dd}/"),
+			ErrorOutputPrefix: pulumi.StringPtr("errors/!{firehose:error-output-type}/year=!{timestamp:yyyy}/month=!{timestamp:MM}/day=!{timestamp:dd}/"),
+			BufferingInterval: pulumi.IntPtr(300),
+			BufferingSize:     pulumi.IntPtr(64),
+		},
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	logsRole, err := iam.NewRole(ctx, fmt.Sprintf("%s-logs-role", name), &iam.RoleArgs{
+		AssumeRolePolicy: pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {
+        "Service": "logs.%s.amazonaws.com"
+      },
+      "Action": "sts:AssumeRole",
+      "Condition": {
+        "ArnLike": {
+          "aws:SourceArn": "%s:*"
+        }
+      }
+    }
+  ]
+}`, region, logGroup.Arn),
+		InlinePolicies: iam.RoleInlinePolicyArray{
+			iam.RoleInlinePolicyArgs{
+				Name: pulumi.StringPtr("PutAuditArchiveRecords"),
+				Policy: pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": [
+        "firehose:PutRecord",
+        "firehose:PutRecordBatch"
+      ],
+      "Resource": "%s"
+    }
+  ]
+}`, streamArn),
+			},
+		},
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = cloudwatch.NewLogSubscriptionFilter(ctx, fmt.Sprintf("%s-subscription", name), &cloudwatch.LogSubscriptionFilterArgs{
+		Name:           pulumi.StringPtr(fmt.Sprintf("%s-audit-archive", name)),
+		LogGroup:       logGroup.Name,
+		FilterPattern:  pulumi.String(archiveArgs.FilterPattern),
+		DestinationArn: deliveryStream.Arn,
+		RoleArn:        logsRole.Arn,
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	componentResource.Bucket = bucket
+	componentResource.DeliveryStream = deliveryStream
+
+	ctx.Export("AuditArchiveBucket", bucket.Bucket)
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
@@ -18,11 +19,63 @@ type PrincipalCluster struct {
 	IssuerUrlWithoutPrefix pulumi.StringOutput
 	oidcProvider           *iam.OpenIdConnectProvider
 	Cluster                *eks.Cluster
+	PrivateSubnetsIds      pulumi.StringArrayOutput
+	PublicSubnetsIds       pulumi.StringArrayOutput
+	// Desired control plane version, node groups and addons follow it
+	KubernetesVersion string
+	// Resolves once the control plane runs KubernetesVersion
+	Version pulumi.StringOutput
+	// false on the first deployment of the stack
+	Deployed bool
+	// Empty unless secrets encryption is enabled
+	SecretsKmsKeyArn pulumi.StringOutput
+	// nil without LogTypes
+	LogGroup *cloudwatch.LogGroup
+	logTypes []string
+	// IpFamilyIpv4 or IpFamilyIpv6, node and CNI roles pick their policy from it
+	IpFamily string
+	// Attached to node group launch templates, add rules here for workloads
+	NodeSecurityGroup *ec2.SecurityGroup
+	// SecurityGroupPolicy can be used, node groups must be on branch ENI instance types
+	PodSecurityGroups bool
+	name              string
 }
 
 type PrincipalClusterArgs struct {
 	SubnetsIds pulumi.StringArrayInput
 	VpcId      pulumi.StringInput
+	// Where node groups are placed, see nodegroup.OpenNodeGroupArgs
+	PrivateSubnetsIds pulumi.StringArrayInput
+	PublicSubnetsIds  pulumi.StringArrayInput
+	// eg. "1.28", defaults to DefaultVersion. Upgrades must go one minor at a time
+	Version string
+	// API server endpoint: EndpointPublic (default), EndpointPublicRestricted or EndpointPrivate,
+	// the fields below override the preset
+	EndpointAccess        string
+	EndpointPrivateAccess *bool
+	EndpointPublicAccess  *bool
+	PublicAccessCidrs     []string
+	// Extra security groups for the control plane ENIs
+	SecurityGroupIds pulumi.StringArrayInput
+	// Required by a private-only endpoint, one of them
+	VpcEndpointsConfigured bool
+	BastionSecurityGroupId pulumi.StringInput
+	// Envelope encryption of kubernetes secrets, creates a rotated KMS key
+	// unless SecretsKmsKeyArn brings an existing one
+	SecretsEncryption bool
+	SecretsKmsKeyArn  pulumi.StringInput
+	// Control plane logs: api, audit, authenticator, controllerManager, scheduler.
+	// The log group is pre-created with LogRetentionDays (DefaultLogRetentionDays) and LogKmsKeyArn
+	LogTypes         []string
+	LogRetentionDays int
+	LogKmsKeyArn     pulumi.StringInput
+	// IpFamilyIpv4 (default) or IpFamilyIpv6, ipv6 needs dual-stack subnets.
+	// ServiceIpv4Cidr avoids overlaps with peered VPCs, ipv4 only
+	IpFamily        string
+	ServiceIpv4Cidr string
+	// Security groups for pods (SecurityGroupPolicy), attaches AmazonEKSVPCResourceController to the cluster role.
+	// The VPC CNI needs podEni too
+	PodSecurityGroups bool
 }
 
 func NewPrincipalCluster(ctx *pulumi.Context, name string, args *PrincipalClusterArgs, opts ...pulumi.ResourceOption) (*PrincipalCluster, error) {
@@ -34,15 +87,60 @@ func NewPrincipalCluster(ctx *pulumi.Context, name string, args *PrincipalCluste
 
 	cfg := config.New(ctx, "aws")
 	region := cfg.Require("region")
+	account := config.New(ctx, "").GetSecret("account")
+
+	version := args.Version
+	if version == "" {
+		version = DefaultVersion
+	}
+
+	if _, _, err := ParseVersion(version); err != nil {
+		return nil, err
+	}
+
+	access, err := resolveEndpointAccess(args)
+	if err != nil {
+		return nil, fmt.Errorf("cluster %q: %w", name, err)
+	}
+
+	if err := validateNetwork(args); err != nil {
+		return nil, fmt.Errorf("cluster %q: %w", name, err)
+	}
+
+	ipFamily := args.IpFamily
+	if ipFamily == "" {
+		ipFamily = IpFamilyIpv4
+	}
+
+	if err := validateLogging(args); err != nil {
+		return nil, fmt.Errorf("cluster %q: %w", name, err)
+	}
+
+	deployed, found, err := deployedVersion(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		if err := CheckUpgrade(deployed, version); err != nil {
+			return nil, fmt.Errorf("cluster %q: %w", name, err)
+		}
+	}
 
 	// <package>:<module>:<type>
-	err := ctx.RegisterComponentResource("my-own-cluster:cluster:PrincipalCluster", name, componentResource, opts...)
+	err = ctx.RegisterComponentResource("my-own-cluster:cluster:PrincipalCluster", name, componentResource, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	clusterPolicyArns := []string{"arn:aws:iam::aws:policy/AmazonEKSClusterPolicy"}
+	if args.PodSecurityGroups {
+		// the VPC resource controller attaches the branch ENIs to the nodes
+		clusterPolicyArns = append(clusterPolicyArns, "arn:aws:iam::aws:policy/AmazonEKSVPCResourceController")
+	}
+
 	clusterrole, err := iam.NewRole(ctx, fmt.Sprintf("%s-eks-cluster-role", name), &iam.RoleArgs{
-		ManagedPolicyArns: pulumi.ToStringArray([]string{"arn:aws:iam::aws:policy/AmazonEKSClusterPolicy"}),
+		ManagedPolicyArns: pulumi.ToStringArray(clusterPolicyArns),
 		AssumeRolePolicy: pulumi.String(`{
   "Version": "2012-10-17",
   "Statement": [
@@ -61,63 +159,106 @@ func NewPrincipalCluster(ctx *pulumi.Context, name string, args *PrincipalCluste
 		return nil, err
 	}
 
+	secretsKmsKeyArn := pulumi.String("").ToStringOutput()
+	var encryptionConfig eks.ClusterEncryptionConfigPtrInput
+	var clusterDependencies []pulumi.Resource
+
+	if args.SecretsKmsKeyArn != nil {
+		secretsKmsKeyArn = args.SecretsKmsKeyArn.ToStringOutput()
+
+		secretsKeyUse, err := allowSecretsKey(ctx, name, secretsKmsKeyArn, clusterrole, componentResource)
+		if err != nil {
+			return nil, err
+		}
+		clusterDependencies = append(clusterDependencies, secretsKeyUse)
+	} else if args.SecretsEncryption {
+		key, err := newSecretsKey(ctx, name, account, clusterrole, componentResource)
+		if err != nil {
+			return nil, err
+		}
+		secretsKmsKeyArn = key.Arn
+	}
+
+	if args.SecretsEncryption || args.SecretsKmsKeyArn != nil {
+		encryptionConfig = eks.ClusterEncryptionConfigArgs{
+			Resources: pulumi.ToStringArray([]string{"secrets"}),
+			Provider: eks.ClusterEncryptionConfigProviderArgs{
+				KeyArn: secretsKmsKeyArn,
+			},
+		}
+	}
+
+	var logGroup *cloudwatch.LogGroup
+	if len(args.LogTypes) > 0 {
+		logGroup, err = newControlPlaneLogGroup(ctx, name, args, componentResource)
+		if err != nil {
+			return nil, err
+		}
+		clusterDependencies = append(clusterDependencies, logGroup)
+	}
+
+	clusterOpts := []pulumi.ResourceOption{pulumi.Parent(componentResource), pulumi.DependsOn(clusterDependencies)}
+
+	networkConfig := eks.ClusterKubernetesNetworkConfigArgs{}
+	subnetsIds := args.SubnetsIds.ToStringArrayOutput()
+
+	if ipFamily == IpFamilyIpv6 {
+		networkConfig.IpFamily = pulumi.StringPtr(IpFamilyIpv6)
+		subnetsIds = requireDualStack(ctx, args.SubnetsIds)
+	}
+	if args.ServiceIpv4Cidr != "" {
+		networkConfig.ServiceIpv4Cidr = pulumi.StringPtr(args.ServiceIpv4Cidr)
+	}
+
 	k8scluster, err := eks.NewCluster(ctx, fmt.Sprintf("%s-eks-cluster", name), &eks.ClusterArgs{
 		Name:                    pulumi.StringPtr(fmt.Sprintf("%s", name)),
-		Version:                 pulumi.StringPtr("1.27"), //TODO: update to 1.28
-		KubernetesNetworkConfig: eks.ClusterKubernetesNetworkConfigArgs{},
+		Version:                 pulumi.StringPtr(version),
+		KubernetesNetworkConfig: networkConfig,
 		VpcConfig: eks.ClusterVpcConfigArgs{
 			// VpcId:     k8svpc.ID(),
-			SubnetIds: args.SubnetsIds, //implicit vpc
+			SubnetIds:             subnetsIds, //implicit vpc
+			EndpointPrivateAccess: pulumi.BoolPtr(access.private),
+			EndpointPublicAccess:  pulumi.BoolPtr(access.public),
+			PublicAccessCidrs:     pulumi.ToStringArray(access.cidrs),
+			SecurityGroupIds:      args.SecurityGroupIds,
 		},
-		RoleArn: clusterrole.Arn,
+		RoleArn:                clusterrole.Arn,
+		EncryptionConfig:       encryptionConfig,
+		EnabledClusterLogTypes: pulumi.ToStringArray(args.LogTypes),
 		Tags: pulumi.ToStringMap(map[string]string{
 			"karpenter.sh/discovery": fmt.Sprintf("%s", name),
 		}),
-	}, pulumi.Parent(componentResource))
+	}, clusterOpts...)
 
 	if err != nil {
 		return nil, err
 	}
 
-	//It is a best practice tag subnets that the cluster is using
-	args.SubnetsIds.ToStringArrayOutput().ApplyT(func(subnets []string) error {
-		aux := 0
-		for _, subnetId := range subnets {
-			_, err = ec2.NewTag(ctx, fmt.Sprintf("%s-tag-subnets-with-cluster-%v", name, aux), &ec2.TagArgs{
-				Key:        pulumi.Sprintf("kubernetes.io/cluster/%s", k8scluster.Name),
-				ResourceId: pulumi.String(subnetId),
-				Value:      pulumi.String("owned"),
-			}, pulumi.Parent(k8scluster))
-
-			if err != nil {
-				return err
-			}
-			_, err = ec2.NewTag(ctx, fmt.Sprintf("%s-tag-subnets-karpenter-discovery-%v", name, aux), &ec2.TagArgs{
-				Key:        pulumi.String("karpenter.sh/discovery"),
-				ResourceId: pulumi.String(subnetId),
-				Value:      k8scluster.Name,
-			}, pulumi.Parent(k8scluster))
-
-			if err != nil {
-				return err
-			}
-
-			aux = aux + 1
-		}
-		return nil
-	})
-
-	//Karpenter need to know which segurity groups can use by a tag
-	_, err = ec2.NewTag(ctx, fmt.Sprintf("%s-TagClusterSecurityGroup", name), &ec2.TagArgs{
-		ResourceId: k8scluster.VpcConfig.ClusterSecurityGroupId().Elem().ToStringOutput(),
-		Key:        pulumi.String("karpenter.sh/discovery"),
-		Value:      k8scluster.Name,
-	}, pulumi.Parent(k8scluster), pulumi.DependsOn([]pulumi.Resource{k8scluster}))
+	//It is a best practice tag subnets that the cluster is using, see SubnetTags
 
+	//Karpenter need to know which segurity groups can use by a tag, nodes get their own group instead of the cluster one
+	nodeSecurityGroup, err := newNodeSecurityGroup(ctx, name, args.VpcId, k8scluster, componentResource)
 	if err != nil {
 		return nil, err
 	}
 
+	//kubectl and pulumi reach a private endpoint through the bastion
+	if args.BastionSecurityGroupId != nil {
+		_, err = ec2.NewSecurityGroupRule(ctx, fmt.Sprintf("%s-bastion-to-api-server", name), &ec2.SecurityGroupRuleArgs{
+			Type:                  pulumi.String("ingress"),
+			Description:           pulumi.StringPtr("bastion to kubernetes api server"),
+			SecurityGroupId:       k8scluster.VpcConfig.ClusterSecurityGroupId().Elem().ToStringOutput(),
+			SourceSecurityGroupId: args.BastionSecurityGroupId,
+			Protocol:              pulumi.String("tcp"),
+			FromPort:              pulumi.Int(443),
+			ToPort:                pulumi.Int(443),
+		}, pulumi.Parent(k8scluster))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	//Create OICD Provider
 	IssuerUrl := k8scluster.Identities.Index(pulumi.Int(0)).Oidcs().Index(pulumi.Int(0)).Issuer()
 
@@ -176,12 +317,27 @@ func NewPrincipalCluster(ctx *pulumi.Context, name string, args *PrincipalCluste
 	componentResource.oidcProvider = oidcProvider
 	componentResource.IssuerUrlWithoutPrefix = IssuerUrlWithoutPrefix
 	componentResource.Cluster = k8scluster
+	componentResource.PrivateSubnetsIds = toStringArrayOutput(args.PrivateSubnetsIds)
+	componentResource.PublicSubnetsIds = toStringArrayOutput(args.PublicSubnetsIds)
+	componentResource.KubernetesVersion = version
+	componentResource.Version = k8scluster.Version
+	componentResource.Deployed = found
+	componentResource.SecretsKmsKeyArn = secretsKmsKeyArn
+	componentResource.LogGroup = logGroup
+	componentResource.logTypes = args.LogTypes
+	componentResource.IpFamily = ipFamily
+	componentResource.NodeSecurityGroup = nodeSecurityGroup
+	componentResource.PodSecurityGroups = args.PodSecurityGroups
+	componentResource.name = name
 
 	ctx.Export("kubeconfig", kubeconfig)
 	ctx.Export("IssuerUrl", IssuerUrl)
 	ctx.Export("IssuerUrlWithoutPrefix", IssuerUrlWithoutPrefix)
 	ctx.Export("ClusterSecurityGroupId", k8scluster.VpcConfig.ClusterSecurityGroupId())
-	ctx.Export("ClusterName", k8scluster.Name)
+	ctx.Export(outputClusterName, k8scluster.Name)
+	ctx.Export("NodeSecurityGroupId", nodeSecurityGroup.ID())
+	ctx.Export(outputKubernetesVersion, k8scluster.Version)
+	ctx.Export("SecretsKmsKeyArn", secretsKmsKeyArn)
 
 	err = ctx.RegisterResourceOutputs(componentResource, pulumi.Map{
 		"ClusterSecurityGroupId": k8scluster.VpcConfig.SecurityGroupIds(),
@@ -192,3 +348,10 @@ func NewPrincipalCluster(ctx *pulumi.Context, name string, args *PrincipalCluste
 
 	return componentResource, nil
 }
+
+func toStringArrayOutput(in pulumi.StringArrayInput) pulumi.StringArrayOutput {
+	if in == nil {
+		return pulumi.StringArray{}.ToStringArrayOutput()
+	}
+	return in.ToStringArrayOutput()
+}
@@ -0,0 +1,88 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/kms"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// newSecretsKey creates the customer managed key used for envelope encryption of kubernetes secrets.
+// The account keeps administration, the cluster role only gets what EKS needs to use it
+// https://docs.aws.amazon.com/eks/latest/userguide/enable-kms.html
+func newSecretsKey(ctx *pulumi.Context, name string, account pulumi.StringOutput, clusterRole *iam.Role, parent pulumi.Resource) (*kms.Key, error) {
+	key, err := kms.NewKey(ctx, fmt.Sprintf("%s-secrets-key", name), &kms.KeyArgs{
+		Description:          pulumi.StringPtr(fmt.Sprintf("%s kubernetes secrets envelope encryption", name)),
+		EnableKeyRotation:    pulumi.BoolPtr(true),
+		DeletionWindowInDays: pulumi.IntPtr(30),
+		Policy: pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "AllowAccountAdministration",
+      "Effect": "Allow",
+      "Principal": {
+        "AWS": "arn:aws:iam::%s:root"
+      },
+      "Action": "kms:*",
+      "Resource": "*"
+    },
+    {
+      "Sid": "AllowClusterRoleUse",
+      "Effect": "Allow",
+      "Principal": {
+        "AWS": "%s"
+      },
+      "Action": [
+        "kms:Encrypt",
+        "kms:Decrypt",
+        "kms:DescribeKey",
+        "kms:ListGrants",
+        "kms:CreateGrant"
+      ],
+      "Resource": "*"
+    }
+  ]
+}`, account, clusterRole.Arn),
+	}, pulumi.Parent(parent))
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = kms.NewAlias(ctx, fmt.Sprintf("%s-secrets-key-alias", name), &kms.AliasArgs{
+		Name:        pulumi.StringPtr(fmt.Sprintf("alias/%s-eks-secrets", name)),
+		TargetKeyId: key.KeyId,
+	}, pulumi.Parent(key))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// allowSecretsKey lets the cluster role use a key created outside this stack,
+// its key policy still has to delegate to IAM. The cluster must depend on it, CreateCluster checks the key
+func allowSecretsKey(ctx *pulumi.Context, name string, keyArn pulumi.StringInput, clusterRole *iam.Role, parent pulumi.Resource) (*iam.RolePolicy, error) {
+	return iam.NewRolePolicy(ctx, fmt.Sprintf("%s-secrets-key-use", name), &iam.RolePolicyArgs{
+		Role: clusterRole.Name,
+		Policy: pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": [
+        "kms:Encrypt",
+        "kms:Decrypt",
+        "kms:DescribeKey",
+        "kms:ListGrants",
+        "kms:CreateGrant"
+      ],
+      "Resource": "%s"
+    }
+  ]
+}`, keyArn),
+	}, pulumi.Parent(parent))
+}
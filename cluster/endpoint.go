@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// API server endpoint presets for PrincipalClusterArgs.EndpointAccess
+const (
+	// EKS defaults, public endpoint open to 0.0.0.0/0
+	EndpointPublic = "public"
+	// public endpoint only for PublicAccessCidrs, nodes reach the API server through the private endpoint
+	EndpointPublicRestricted = "public-restricted"
+	// no public endpoint, needs VPC endpoints (nodes) and a bastion path (kubectl, pulumi)
+	EndpointPrivate = "private"
+)
+
+type endpointAccess struct {
+	private bool
+	public  bool
+	cidrs   []string
+}
+
+func resolveEndpointAccess(args *PrincipalClusterArgs) (endpointAccess, error) {
+	var access endpointAccess
+
+	switch args.EndpointAccess {
+	case "", EndpointPublic:
+		access = endpointAccess{public: true, private: false, cidrs: []string{"0.0.0.0/0"}}
+	case EndpointPublicRestricted:
+		access = endpointAccess{public: true, private: true}
+	case EndpointPrivate:
+		access = endpointAccess{public: false, private: true}
+	default:
+		return access, fmt.Errorf("endpointAccess %q must be one of %v", args.EndpointAccess, []string{EndpointPublic, EndpointPublicRestricted, EndpointPrivate})
+	}
+
+	// explicit values win over the preset
+	if args.EndpointPrivateAccess != nil {
+		access.private = *args.EndpointPrivateAccess
+	}
+	if args.EndpointPublicAccess != nil {
+		access.public = *args.EndpointPublicAccess
+	}
+	if len(args.PublicAccessCidrs) > 0 {
+		access.cidrs = args.PublicAccessCidrs
+	}
+
+	if !access.public && !access.private {
+		return access, errors.New("at least one of the public or private API endpoints must be enabled")
+	}
+
+	for _, cidr := range access.cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return access, fmt.Errorf("publicAccessCidrs: %w", err)
+		}
+	}
+
+	if args.EndpointAccess == EndpointPublicRestricted {
+		if len(access.cidrs) == 0 {
+			return access, errors.New("endpointAccess public-restricted needs publicAccessCidrs")
+		}
+		for _, cidr := range access.cidrs {
+			if cidr == "0.0.0.0/0" {
+				return access, errors.New("endpointAccess public-restricted can't allow 0.0.0.0/0")
+			}
+		}
+	}
+
+	if !access.public {
+		access.cidrs = nil
+		if !args.VpcEndpointsConfigured && args.BastionSecurityGroupId == nil {
+			return access, errors.New("a private-only API endpoint needs VpcEndpointsConfigured or BastionSecurityGroupId, otherwise nothing outside the VPC can reach the cluster")
+		}
+	}
+
+	return access, nil
+}
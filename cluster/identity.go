@@ -0,0 +1,176 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+)
+
+// How workloads get their AWS credentials
+const (
+	// The role trusts the cluster OIDC provider and the service account is annotated with it
+	IdentityModeIrsa = "irsa"
+	// The role trusts pods.eks.amazonaws.com and a PodIdentityAssociation binds it to the service account.
+	// Needs the eks-pod-identity-agent addon, doesn't work on Fargate
+	IdentityModePodIdentity = "pod-identity"
+)
+
+// the same for every cluster, blue/green clusters can share the role
+const podIdentityTrustPolicy = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {
+        "Service": "pods.eks.amazonaws.com"
+      },
+      "Action": [
+        "sts:AssumeRole",
+        "sts:TagSession"
+      ]
+    }
+  ]
+}`
+
+func ValidateIdentityMode(identityMode string) error {
+	if identityMode != "" && identityMode != IdentityModeIrsa && identityMode != IdentityModePodIdentity {
+		return fmt.Errorf("identity mode %q must be %s or %s", identityMode, IdentityModeIrsa, IdentityModePodIdentity)
+	}
+	return nil
+}
+
+// IrsaTrustPolicy lets the service account, "namespace:name" with wildcards allowed, assume the role through the cluster OIDC provider
+func IrsaTrustPolicy(account pulumi.StringInput, issuerUrlWithoutPrefix pulumi.StringInput, serviceAccount string) pulumi.StringOutput {
+	subjectCondition := "StringEquals"
+	if strings.Contains(serviceAccount, "*") {
+		subjectCondition = "StringLike"
+	}
+
+	return pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {
+        "Federated": "arn:aws:iam::%s:oidc-provider/%s"
+      },
+      "Action": "sts:AssumeRoleWithWebIdentity",
+      "Condition": {
+        "StringEquals": {
+          "%s:aud": "sts.amazonaws.com"
+        },
+        "%s": {
+          "%s:sub": "system:serviceaccount:%s"
+        }
+      }
+    }
+  ]
+}`, account, issuerUrlWithoutPrefix, issuerUrlWithoutPrefix, subjectCondition, issuerUrlWithoutPrefix, serviceAccount)
+}
+
+// ServiceAccountAnnotations are the ones a service account of that identity mode needs,
+// with Pod Identity the IRSA annotation would win over the association
+func ServiceAccountAnnotations(identityMode string, roleArn pulumi.StringInput) pulumi.StringMap {
+	if identityMode == IdentityModePodIdentity {
+		return pulumi.StringMap{}
+	}
+	return pulumi.StringMap{
+		"eks.amazonaws.com/role-arn":               roleArn,
+		"eks.amazonaws.com/sts-regional-endpoints": pulumi.String("true"),
+	}
+}
+
+// ServiceAccountRole is the role a service account works with
+type ServiceAccountRole struct {
+	Role *iam.Role
+	// Binds the role to the service account with Pod Identity, nil with IRSA
+	Association *eks.PodIdentityAssociation
+}
+
+// Ready is what the pods of the service account have to wait for, credentials don't exist before it
+func (r *ServiceAccountRole) Ready() []pulumi.Resource {
+	if r.Association != nil {
+		return []pulumi.Resource{r.Role, r.Association}
+	}
+	return []pulumi.Resource{r.Role}
+}
+
+type ServiceAccountRoleArgs struct {
+	// IdentityModeIrsa (default) or IdentityModePodIdentity
+	IdentityMode string
+	// Of the association
+	ClusterName pulumi.StringInput
+	// Of the trust policy
+	IssuerUrlWithoutPrefix pulumi.StringInput
+	// "namespace:name", wildcards only with IRSA
+	ServiceAccount    string
+	ManagedPolicyArns pulumi.StringArrayInput
+	InlinePolicies    iam.RoleInlinePolicyArrayInput
+}
+
+// NewServiceAccountRole creates the role the service account works with, trusting the OIDC provider or, with Pod Identity,
+// pods.eks.amazonaws.com plus the association to the service account (child of the role)
+func NewServiceAccountRole(ctx *pulumi.Context, name string, args *ServiceAccountRoleArgs, opts ...pulumi.ResourceOption) (*ServiceAccountRole, error) {
+	if args == nil {
+		args = &ServiceAccountRoleArgs{}
+	}
+
+	err := ValidateIdentityMode(args.IdentityMode)
+	if err != nil {
+		return nil, fmt.Errorf("service account role %q: %w", name, err)
+	}
+	serviceAccount := strings.Split(args.ServiceAccount, ":")
+	if len(serviceAccount) != 2 {
+		return nil, fmt.Errorf("service account role %q: ServiceAccount %q must be namespace:name", name, args.ServiceAccount)
+	}
+
+	roleArgs := &iam.RoleArgs{
+		ManagedPolicyArns: args.ManagedPolicyArns,
+		InlinePolicies:    args.InlinePolicies,
+	}
+
+	if args.IdentityMode == IdentityModePodIdentity {
+		if strings.Contains(args.ServiceAccount, "*") {
+			return nil, fmt.Errorf("service account role %q: Pod Identity associates a single service account, not %q", name, args.ServiceAccount)
+		}
+		if args.ClusterName == nil {
+			return nil, fmt.Errorf("service account role %q: Pod Identity needs ClusterName", name)
+		}
+		roleArgs.AssumeRolePolicy = pulumi.String(podIdentityTrustPolicy)
+	} else {
+		if args.IssuerUrlWithoutPrefix == nil {
+			return nil, fmt.Errorf("service account role %q: IRSA needs IssuerUrlWithoutPrefix", name)
+		}
+		account := config.New(ctx, "").GetSecret("account")
+		roleArgs.AssumeRolePolicy = IrsaTrustPolicy(account, args.IssuerUrlWithoutPrefix, args.ServiceAccount)
+	}
+
+	role, err := iam.NewRole(ctx, name, roleArgs, opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	serviceAccountRole := &ServiceAccountRole{Role: role}
+
+	if args.IdentityMode == IdentityModePodIdentity {
+		association, err := eks.NewPodIdentityAssociation(ctx, fmt.Sprintf("%s-pod-identity", name), &eks.PodIdentityAssociationArgs{
+			ClusterName:    args.ClusterName,
+			Namespace:      pulumi.String(serviceAccount[0]),
+			ServiceAccount: pulumi.String(serviceAccount[1]),
+			RoleArn:        role.Arn,
+		}, pulumi.Parent(role))
+
+		if err != nil {
+			return nil, err
+		}
+
+		serviceAccountRole.Association = association
+	}
+
+	return serviceAccountRole, nil
+}
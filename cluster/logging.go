@@ -0,0 +1,51 @@
+package cluster
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// Control plane log types accepted by EnabledClusterLogTypes
+var controlPlaneLogTypes = []string{"api", "audit", "authenticator", "controllerManager", "scheduler"}
+
+// DefaultLogRetentionDays is used when PrincipalClusterArgs.LogRetentionDays is 0
+const DefaultLogRetentionDays = 90
+
+// Values CloudWatch Logs accepts for retention
+var logRetentionDays = []int{1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1096, 1827, 2192, 2557, 2922, 3288, 3653}
+
+func validateLogging(args *PrincipalClusterArgs) error {
+	for _, logType := range args.LogTypes {
+		if !slices.Contains(controlPlaneLogTypes, logType) {
+			return fmt.Errorf("log type %q must be one of %v", logType, controlPlaneLogTypes)
+		}
+	}
+	if args.LogRetentionDays != 0 && !slices.Contains(logRetentionDays, args.LogRetentionDays) {
+		return fmt.Errorf("log retention %d days must be one of %v", args.LogRetentionDays, logRetentionDays)
+	}
+	return nil
+}
+
+// newControlPlaneLogGroup creates the group EKS writes to before the cluster exists,
+// otherwise EKS creates it itself without retention and it has to be imported
+func newControlPlaneLogGroup(ctx *pulumi.Context, name string, args *PrincipalClusterArgs, parent pulumi.Resource) (*cloudwatch.LogGroup, error) {
+	retention := args.LogRetentionDays
+	if retention == 0 {
+		retention = DefaultLogRetentionDays
+	}
+
+	logGroupArgs := &cloudwatch.LogGroupArgs{
+		Name:            pulumi.StringPtr(fmt.Sprintf("/aws/eks/%s/cluster", name)),
+		RetentionInDays: pulumi.IntPtr(retention),
+	}
+
+	// the key policy must allow logs.<region>.amazonaws.com
+	if args.LogKmsKeyArn != nil {
+		logGroupArgs.KmsKeyId = args.LogKmsKeyArn.ToStringOutput().ToStringPtrOutput()
+	}
+
+	return cloudwatch.NewLogGroup(ctx, fmt.Sprintf("%s-control-plane-logs", name), logGroupArgs, pulumi.Parent(parent))
+}
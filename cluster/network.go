@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// PrincipalClusterArgs.IpFamily values
+const (
+	IpFamilyIpv4 = "ipv4"
+	IpFamilyIpv6 = "ipv6"
+)
+
+// CniIpv6PolicyDocument replaces AmazonEKS_CNI_Policy on ipv6 clusters, the managed policy only covers ipv4
+// https://docs.aws.amazon.com/eks/latest/userguide/cni-iam-role.html#cni-iam-role-create-ipv6-policy
+const CniIpv6PolicyDocument = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": [
+        "ec2:AssignIpv6Addresses",
+        "ec2:DescribeInstances",
+        "ec2:DescribeTags",
+        "ec2:DescribeNetworkInterfaces",
+        "ec2:DescribeInstanceTypes"
+      ],
+      "Resource": "*"
+    },
+    {
+      "Effect": "Allow",
+      "Action": [
+        "ec2:CreateTags"
+      ],
+      "Resource": [
+        "arn:aws:ec2:*:*:network-interface/*"
+      ]
+    }
+  ]
+}`
+
+// EKS only takes service CIDRs from these ranges, /12 to /24
+var serviceCidrRanges = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
+func validateNetwork(args *PrincipalClusterArgs) error {
+	switch args.IpFamily {
+	case "", IpFamilyIpv4:
+	case IpFamilyIpv6:
+		if args.ServiceIpv4Cidr != "" {
+			return errors.New("serviceIpv4Cidr can't be set on an ipv6 cluster, EKS assigns the service range")
+		}
+		return nil
+	default:
+		return fmt.Errorf("ipFamily %q must be %s or %s", args.IpFamily, IpFamilyIpv4, IpFamilyIpv6)
+	}
+
+	if args.ServiceIpv4Cidr == "" {
+		return nil
+	}
+
+	ip, network, err := net.ParseCIDR(args.ServiceIpv4Cidr)
+	if err != nil || ip.To4() == nil {
+		return fmt.Errorf("serviceIpv4Cidr %q is not an ipv4 CIDR", args.ServiceIpv4Cidr)
+	}
+	if ones, _ := network.Mask.Size(); ones < 12 || ones > 24 {
+		return fmt.Errorf("serviceIpv4Cidr %q must be between /12 and /24", args.ServiceIpv4Cidr)
+	}
+	for _, cidr := range serviceCidrRanges {
+		_, allowed, _ := net.ParseCIDR(cidr)
+		allowedOnes, _ := allowed.Mask.Size()
+		ones, _ := network.Mask.Size()
+		if allowed.Contains(network.IP) && ones >= allowedOnes {
+			return nil
+		}
+	}
+	return fmt.Errorf("serviceIpv4Cidr %q must be inside one of %v", args.ServiceIpv4Cidr, serviceCidrRanges)
+}
+
+// requireDualStack fails the deployment if any subnet lacks an ipv6 CIDR, ipv6 clusters need dual-stack subnets
+func requireDualStack(ctx *pulumi.Context, subnets pulumi.StringArrayInput) pulumi.StringArrayOutput {
+	return subnets.ToStringArrayOutput().ApplyT(func(ids []string) ([]string, error) {
+		for _, id := range ids {
+			subnetId := id
+			subnet, err := ec2.LookupSubnet(ctx, &ec2.LookupSubnetArgs{Id: &subnetId})
+			if err != nil {
+				return nil, err
+			}
+			if subnet.Ipv6CidrBlock == "" {
+				return nil, fmt.Errorf("subnet %s has no ipv6 CIDR, an ipv6 cluster needs dual-stack subnets from the network stack", id)
+			}
+		}
+		return ids, nil
+	}).(pulumi.StringArrayOutput)
+}
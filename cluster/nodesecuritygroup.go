@@ -0,0 +1,114 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+type securityGroupRule struct {
+	name        string
+	description string
+	protocol    string
+	fromPort    int
+	toPort      int
+}
+
+// control plane -> nodes
+var controlPlaneToNodeRules = []securityGroupRule{
+	{"kubelet", "control plane to kubelet", "tcp", 10250, 10250},
+	{"webhooks", "control plane to webhooks", "tcp", 443, 443},
+	{"webhooks-9443", "control plane to webhooks (elb controller, karpenter)", "tcp", 9443, 9443},
+}
+
+// nodes -> cluster security group, used by the control plane ENIs and Fargate pods
+var nodeToClusterRules = []securityGroupRule{
+	{"api-server", "nodes to kubernetes api server", "tcp", 443, 443},
+	{"dns-tcp", "nodes to coredns on fargate", "tcp", 53, 53},
+	{"dns-udp", "nodes to coredns on fargate", "udp", 53, 53},
+}
+
+// newNodeSecurityGroup replaces the EKS created cluster security group on the nodes so it can be restricted
+// and extended per workload. It carries the karpenter discovery tag and the cluster tag the elb controller looks for
+func newNodeSecurityGroup(ctx *pulumi.Context, name string, vpcId pulumi.StringInput, k8scluster *eks.Cluster, parent pulumi.Resource) (*ec2.SecurityGroup, error) {
+	nodeSecurityGroup, err := ec2.NewSecurityGroup(ctx, fmt.Sprintf("%s-node-security-group", name), &ec2.SecurityGroupArgs{
+		Description: pulumi.StringPtr(fmt.Sprintf("%s worker nodes", name)),
+		VpcId:       vpcId,
+		Tags: pulumi.StringMap{
+			"Name":                   pulumi.Sprintf("%s-node", name),
+			"karpenter.sh/discovery": k8scluster.Name,
+			fmt.Sprintf("kubernetes.io/cluster/%s", name): pulumi.String("owned"),
+		},
+	}, pulumi.Parent(parent))
+
+	if err != nil {
+		return nil, err
+	}
+
+	clusterSecurityGroupId := k8scluster.VpcConfig.ClusterSecurityGroupId().Elem().ToStringOutput()
+
+	_, err = ec2.NewSecurityGroupRule(ctx, fmt.Sprintf("%s-node-to-node", name), &ec2.SecurityGroupRuleArgs{
+		Type:            pulumi.String("ingress"),
+		Description:     pulumi.StringPtr("node to node"),
+		SecurityGroupId: nodeSecurityGroup.ID(),
+		Self:            pulumi.BoolPtr(true),
+		Protocol:        pulumi.String("-1"),
+		FromPort:        pulumi.Int(0),
+		ToPort:          pulumi.Int(0),
+	}, pulumi.Parent(nodeSecurityGroup))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range controlPlaneToNodeRules {
+		_, err = ec2.NewSecurityGroupRule(ctx, fmt.Sprintf("%s-control-plane-to-node-%s", name, rule.name), &ec2.SecurityGroupRuleArgs{
+			Type:                  pulumi.String("ingress"),
+			Description:           pulumi.StringPtr(rule.description),
+			SecurityGroupId:       nodeSecurityGroup.ID(),
+			SourceSecurityGroupId: clusterSecurityGroupId,
+			Protocol:              pulumi.String(rule.protocol),
+			FromPort:              pulumi.Int(rule.fromPort),
+			ToPort:                pulumi.Int(rule.toPort),
+		}, pulumi.Parent(nodeSecurityGroup))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, rule := range nodeToClusterRules {
+		_, err = ec2.NewSecurityGroupRule(ctx, fmt.Sprintf("%s-node-to-cluster-%s", name, rule.name), &ec2.SecurityGroupRuleArgs{
+			Type:                  pulumi.String("ingress"),
+			Description:           pulumi.StringPtr(rule.description),
+			SecurityGroupId:       clusterSecurityGroupId,
+			SourceSecurityGroupId: nodeSecurityGroup.ID(),
+			Protocol:              pulumi.String(rule.protocol),
+			FromPort:              pulumi.Int(rule.fromPort),
+			ToPort:                pulumi.Int(rule.toPort),
+		}, pulumi.Parent(nodeSecurityGroup))
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = ec2.NewSecurityGroupRule(ctx, fmt.Sprintf("%s-node-egress", name), &ec2.SecurityGroupRuleArgs{
+		Type:            pulumi.String("egress"),
+		Description:     pulumi.StringPtr("nodes to anywhere"),
+		SecurityGroupId: nodeSecurityGroup.ID(),
+		CidrBlocks:      pulumi.ToStringArray([]string{"0.0.0.0/0"}),
+		Ipv6CidrBlocks:  pulumi.ToStringArray([]string{"::/0"}),
+		Protocol:        pulumi.String("-1"),
+		FromPort:        pulumi.Int(0),
+		ToPort:          pulumi.Int(0),
+	}, pulumi.Parent(nodeSecurityGroup))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeSecurityGroup, nil
+}
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes"
+	"github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/apiextensions"
+	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// SecurityGroupPolicy gives the selected pods their own security groups through a branch ENI, eg. to reach RDS.
+// The pods lose the node security group, so SecurityGroupIds usually includes Cluster.NodeSecurityGroup for DNS and the API server
+// https://docs.aws.amazon.com/eks/latest/userguide/security-groups-for-pods.html
+type SecurityGroupPolicy struct {
+	pulumi.ResourceState
+}
+
+type SecurityGroupPolicyArgs struct {
+	Cluster *PrincipalCluster
+	// Namespace of the policy, it only selects pods there
+	Namespace string
+	// Pod labels, empty selects every pod of the namespace
+	PodSelector      map[string]string
+	SecurityGroupIds pulumi.StringArrayInput
+}
+
+func NewSecurityGroupPolicy(ctx *pulumi.Context, name string, args *SecurityGroupPolicyArgs, opts ...pulumi.ResourceOption) (*SecurityGroupPolicy, error) {
+	componentResource := &SecurityGroupPolicy{}
+
+	if args == nil {
+		args = &SecurityGroupPolicyArgs{}
+	}
+
+	if args.Cluster == nil {
+		return nil, fmt.Errorf("security group policy %q: Cluster is required", name)
+	}
+	if !args.Cluster.PodSecurityGroups {
+		return nil, fmt.Errorf("security group policy %q: the cluster needs PodSecurityGroups", name)
+	}
+	if args.Namespace == "" {
+		return nil, fmt.Errorf("security group policy %q: Namespace is required", name)
+	}
+	if args.SecurityGroupIds == nil {
+		return nil, fmt.Errorf("security group policy %q: SecurityGroupIds is required", name)
+	}
+
+	// <package>:<module>:<type>
+	err := ctx.RegisterComponentResource("my-own-cluster:cluster:SecurityGroupPolicy", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = apiextensions.NewCustomResource(ctx, fmt.Sprintf("%s-security-group-policy", name), &apiextensions.CustomResourceArgs{
+		ApiVersion: pulumi.String("vpcresources.k8s.aws/v1beta1"),
+		Kind:       pulumi.String("SecurityGroupPolicy"),
+		Metadata: metav1.ObjectMetaArgs{
+			Name:      pulumi.StringPtr(name),
+			Namespace: pulumi.StringPtr(args.Namespace),
+		},
+		OtherFields: kubernetes.UntypedArgs{
+			"spec": pulumi.Map{
+				"podSelector": pulumi.Map{
+					"matchLabels": pulumi.ToStringMap(args.PodSelector),
+				},
+				"securityGroups": pulumi.Map{
+					"groupIds": args.SecurityGroupIds,
+				},
+			},
+		},
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
@@ -0,0 +1,116 @@
+package cluster
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// SubnetTags tags the network stack subnets for the cluster:
+//   - all: kubernetes.io/cluster/<cluster> = owned
+//   - public: kubernetes.io/role/elb = 1 (internet facing load balancers)
+//   - private: kubernetes.io/role/internal-elb = 1 and karpenter.sh/discovery = <cluster> (nodes)
+//
+// Subnet ids are plain values so every tag is known at preview and keyed by subnet, not by position
+type SubnetTags struct {
+	pulumi.ResourceState
+}
+
+type SubnetTagsArgs struct {
+	Cluster          *PrincipalCluster
+	PublicSubnetIds  []string
+	PrivateSubnetIds []string
+	// Order of PrincipalClusterArgs.SubnetsIds when the tags were created by the cluster itself, indexed by position.
+	// Lets existing stacks adopt those tags instead of deleting them
+	LegacySubnetOrder []string
+}
+
+type subnetTag struct {
+	name  string
+	key   pulumi.StringInput
+	value pulumi.StringInput
+	// resource name the cluster used before, by position
+	legacyName string
+}
+
+func NewSubnetTags(ctx *pulumi.Context, name string, args *SubnetTagsArgs, opts ...pulumi.ResourceOption) (*SubnetTags, error) {
+	componentResource := &SubnetTags{}
+
+	if args == nil {
+		args = &SubnetTagsArgs{}
+	}
+
+	if args.Cluster == nil {
+		return nil, fmt.Errorf("subnet tags %q: Cluster is required", name)
+	}
+
+	if len(args.PrivateSubnetIds) == 0 {
+		return nil, fmt.Errorf("subnet tags %q: at least one private subnet is required", name)
+	}
+
+	for _, subnetId := range args.PublicSubnetIds {
+		if slices.Contains(args.PrivateSubnetIds, subnetId) {
+			return nil, fmt.Errorf("subnet tags %q: subnet %s is listed as public and private", name, subnetId)
+		}
+	}
+
+	// <package>:<module>:<type>
+	err := ctx.RegisterComponentResource("my-own-cluster:cluster:SubnetTags", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := args.Cluster.Cluster.Name
+	ownershipKey := pulumi.Sprintf("kubernetes.io/cluster/%s", clusterName)
+
+	legacyIndex := map[string]int{}
+	for i, subnetId := range args.LegacySubnetOrder {
+		legacyIndex[subnetId] = i
+	}
+
+	tagsBySubnet := map[string][]subnetTag{}
+
+	for _, subnetId := range args.PublicSubnetIds {
+		tagsBySubnet[subnetId] = []subnetTag{
+			{name: "cluster", key: ownershipKey, value: pulumi.String("owned"), legacyName: "tag-subnets-with-cluster"},
+			{name: "elb", key: pulumi.String("kubernetes.io/role/elb"), value: pulumi.String("1")},
+		}
+	}
+
+	for _, subnetId := range args.PrivateSubnetIds {
+		tagsBySubnet[subnetId] = []subnetTag{
+			{name: "cluster", key: ownershipKey, value: pulumi.String("owned"), legacyName: "tag-subnets-with-cluster"},
+			{name: "karpenter-discovery", key: pulumi.String("karpenter.sh/discovery"), value: clusterName, legacyName: "tag-subnets-karpenter-discovery"},
+			{name: "internal-elb", key: pulumi.String("kubernetes.io/role/internal-elb"), value: pulumi.String("1")},
+		}
+	}
+
+	for _, subnetId := range append(append([]string{}, args.PublicSubnetIds...), args.PrivateSubnetIds...) {
+		for _, tag := range tagsBySubnet[subnetId] {
+			tagOpts := []pulumi.ResourceOption{pulumi.Parent(componentResource)}
+
+			if i, ok := legacyIndex[subnetId]; ok && tag.legacyName != "" {
+				tagOpts = append(tagOpts, pulumi.Aliases([]pulumi.Alias{{
+					Name:   pulumi.String(fmt.Sprintf("%s-%s-%v", args.Cluster.name, tag.legacyName, i)),
+					Parent: args.Cluster.Cluster,
+				}}))
+			}
+
+			_, err = ec2.NewTag(ctx, fmt.Sprintf("%s-%s-%s", name, subnetId, tag.name), &ec2.TagArgs{
+				ResourceId: pulumi.String(subnetId),
+				Key:        tag.key,
+				Value:      tag.value,
+			}, tagOpts...)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
@@ -0,0 +1,125 @@
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// DefaultVersion is used when PrincipalClusterArgs.Version is empty
+const DefaultVersion = "1.27"
+
+// ParseVersion reads "1.27" (or "1.27.4", "v1.27") as major and minor
+func ParseVersion(version string) (int, int, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("kubernetes version %q must look like <major>.<minor>", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("kubernetes version %q: %w", version, err)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("kubernetes version %q: %w", version, err)
+	}
+	return major, minor, nil
+}
+
+// CheckUpgrade refuses downgrades and jumps of more than one minor, EKS upgrades the control plane one minor at a time
+func CheckUpgrade(current, desired string) error {
+	currentMajor, currentMinor, err := ParseVersion(current)
+	if err != nil {
+		return err
+	}
+	desiredMajor, desiredMinor, err := ParseVersion(desired)
+	if err != nil {
+		return err
+	}
+	if currentMajor != desiredMajor {
+		return fmt.Errorf("cannot change kubernetes major version from %s to %s", current, desired)
+	}
+	if desiredMinor < currentMinor {
+		return fmt.Errorf("cannot downgrade kubernetes from %s to %s", current, desired)
+	}
+	if desiredMinor-currentMinor > 1 {
+		return fmt.Errorf("cannot upgrade kubernetes from %s to %s, upgrade one minor version at a time (next: %d.%d)", current, desired, currentMajor, currentMinor+1)
+	}
+	return nil
+}
+
+// CheckKubeletSkew applies https://kubernetes.io/releases/version-skew-policy/#kubelet
+// kubelet must not be newer than the control plane and may be up to 2 minors older (3 since 1.28)
+func CheckKubeletSkew(controlPlane, kubelet string) error {
+	controlPlaneMajor, controlPlaneMinor, err := ParseVersion(controlPlane)
+	if err != nil {
+		return err
+	}
+	kubeletMajor, kubeletMinor, err := ParseVersion(kubelet)
+	if err != nil {
+		return err
+	}
+	if controlPlaneMajor != kubeletMajor {
+		return fmt.Errorf("kubelet %s and control plane %s have different major versions", kubelet, controlPlane)
+	}
+	if kubeletMinor > controlPlaneMinor {
+		return fmt.Errorf("kubelet %s must not be newer than the control plane %s", kubelet, controlPlane)
+	}
+	maxSkew := 2
+	if controlPlaneMinor >= 28 {
+		maxSkew = 3
+	}
+	if controlPlaneMinor-kubeletMinor > maxSkew {
+		return fmt.Errorf("kubelet %s is more than %d minor versions older than the control plane %s", kubelet, maxSkew, controlPlane)
+	}
+	return nil
+}
+
+// the control plane version output, read back by deployedVersion
+const outputKubernetesVersion = "KubernetesVersion"
+
+// the cluster name output, stacks deployed before KubernetesVersion was exported still have it
+const outputClusterName = "ClusterName"
+
+// deployedVersion reads the version of the last update from this stack's own outputs, found is false on the first deployment.
+// Stacks without the version output get it from EKS through their cluster name. Previews don't need access to the cluster API
+func deployedVersion(ctx *pulumi.Context, name string) (version string, found bool, err error) {
+	self, err := pulumi.NewStackReference(ctx, fmt.Sprintf("%s-deployed", name), &pulumi.StackReferenceArgs{
+		Name: pulumi.String(fmt.Sprintf("%s/%s/%s", ctx.Organization(), ctx.Project(), ctx.Stack())),
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	details, err := self.GetOutputDetails(outputKubernetesVersion)
+	if err != nil {
+		return "", false, err
+	}
+	if details.Value != nil {
+		version, ok := details.Value.(string)
+		if !ok {
+			return "", false, fmt.Errorf("stack output %q is %T, not a string", outputKubernetesVersion, details.Value)
+		}
+		return version, true, nil
+	}
+
+	details, err = self.GetOutputDetails(outputClusterName)
+	if err != nil {
+		return "", false, err
+	}
+	if details.Value == nil {
+		return "", false, nil
+	}
+	clusterName, ok := details.Value.(string)
+	if !ok {
+		return "", false, fmt.Errorf("stack output %q is %T, not a string", outputClusterName, details.Value)
+	}
+	deployed, err := eks.LookupCluster(ctx, &eks.LookupClusterArgs{Name: clusterName})
+	if err != nil {
+		return "", false, fmt.Errorf("deployed cluster %q: %w", clusterName, err)
+	}
+	return deployed.Version, true, nil
+}
@@ -6,11 +6,12 @@ package complement
 import (
 	"fmt"
 
+	"k8s-cluster-own/cluster"
+
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
 	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
 	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
-	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 )
 
 type ClusterAutoscaling struct {
@@ -19,6 +20,10 @@ type ClusterAutoscaling struct {
 
 type ClusterAutoscalingArgs struct {
 	IssuerUrlWithoutPrefix pulumi.StringInput
+	// Of the Pod Identity association
+	ClusterName pulumi.StringInput
+	// cluster.IdentityModeIrsa (default) or cluster.IdentityModePodIdentity
+	IdentityMode string
 }
 
 func NewClusterAutoscaling(ctx *pulumi.Context, name string, args *ClusterAutoscalingArgs, opts ...pulumi.ResourceOption) (*ClusterAutoscaling, error) {
@@ -36,12 +41,8 @@ func NewClusterAutoscaling(ctx *pulumi.Context, name string, args *ClusterAutosc
 
 	// https://github.com/kubernetes/autoscaler/blob/master/cluster-autoscaler/cloudprovider/aws/examples/cluster-autoscaler-autodiscover.yaml
 
-	cfg := config.New(ctx, "")
-	account := cfg.Require("account")
-
 	serviceAccountName := "cluster-autoscaler"
 	namespace := "kube-system"
-	issuerurlwithoutprefix := args.IssuerUrlWithoutPrefix
 
 	policy := pulumi.Sprintf(`{
   "Version": "2012-10-17",
@@ -73,28 +74,12 @@ func NewClusterAutoscaling(ctx *pulumi.Context, name string, args *ClusterAutosc
   ]
 }`).ToStringPtrOutput()
 
-	trustedPolicy := pulumi.Sprintf(`{
-  "Version": "2012-10-17",
-  "Statement": [
-    {
-      "Effect": "Allow",
-      "Principal": {
-        "Federated": "arn:aws:iam::%s:oidc-provider/%s"
-      },
-      "Action": "sts:AssumeRoleWithWebIdentity",
-      "Condition": {
-        "StringEquals": {
-          "%s:aud": "sts.amazonaws.com",
-          "%s:sub": "system:serviceaccount:%s:%s"
-        }
-      }
-    }
-  ]
-}`, account, issuerurlwithoutprefix, issuerurlwithoutprefix, issuerurlwithoutprefix, namespace, serviceAccountName)
-
 	//Resources Example
-	role, err := iam.NewRole(ctx, fmt.Sprintf("%s-cluster-autoscaler-ASG", name), &iam.RoleArgs{
-		AssumeRolePolicy: trustedPolicy,
+	role, err := cluster.NewServiceAccountRole(ctx, fmt.Sprintf("%s-cluster-autoscaler-ASG", name), &cluster.ServiceAccountRoleArgs{
+		IdentityMode:           args.IdentityMode,
+		ClusterName:            args.ClusterName,
+		IssuerUrlWithoutPrefix: args.IssuerUrlWithoutPrefix,
+		ServiceAccount:         fmt.Sprintf("%s:%s", namespace, serviceAccountName),
 		InlinePolicies: iam.RoleInlinePolicyArray{
 			iam.RoleInlinePolicyArgs{
 				Name:   pulumi.StringPtr("AllowClusterAutoscaling"),
@@ -108,12 +93,9 @@ func NewClusterAutoscaling(ctx *pulumi.Context, name string, args *ClusterAutosc
 	}
 
 	metaArgs := metav1.ObjectMetaArgs{
-		Annotations: pulumi.StringMap{
-			"eks.amazonaws.com/role-arn":               pulumi.Sprintf("%s", role.Arn),
-			"eks.amazonaws.com/sts-regional-endpoints": pulumi.String("true"),
-		},
-		Name:      pulumi.StringPtr(serviceAccountName),
-		Namespace: pulumi.StringPtr(namespace),
+		Annotations: cluster.ServiceAccountAnnotations(args.IdentityMode, role.Role.Arn),
+		Name:        pulumi.StringPtr(serviceAccountName),
+		Namespace:   pulumi.StringPtr(namespace),
 		Labels: pulumi.ToStringMap(map[string]string{
 			"k8s-addon": "cluster-autoscaler.addons.k8s.io",
 			"k8s-app":   "cluster-autoscaler",
@@ -122,7 +104,7 @@ func NewClusterAutoscaling(ctx *pulumi.Context, name string, args *ClusterAutosc
 
 	_, err = corev1.NewServiceAccount(ctx, fmt.Sprintf("%s-cluster-autoscaler", name), &corev1.ServiceAccountArgs{
 		Metadata: metaArgs,
-	}, pulumi.DependsOn([]pulumi.Resource{role}), pulumi.Parent(componentResource))
+	}, pulumi.DependsOn(role.Ready()), pulumi.Parent(componentResource))
 
 	if err != nil {
 		return nil, err
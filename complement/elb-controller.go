@@ -7,12 +7,13 @@ import (
 	"fmt"
 	"os/exec"
 
+	"k8s-cluster-own/cluster"
+
 	corev1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/core/v1"
 	metav1 "github.com/pulumi/pulumi-kubernetes/sdk/v4/go/kubernetes/meta/v1"
 
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
-	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 )
 
 type ElbController struct {
@@ -22,6 +23,8 @@ type ElbController struct {
 type ElbControllerArgs struct {
 	IssuerUrlWithoutPrefix pulumi.StringInput
 	ClusterName            pulumi.StringInput
+	// cluster.IdentityModeIrsa (default) or cluster.IdentityModePodIdentity
+	IdentityMode string
 }
 
 func NewElbController(ctx *pulumi.Context, name string, args *ElbControllerArgs, opts ...pulumi.ResourceOption) (*ElbController, error) {
@@ -37,11 +40,12 @@ func NewElbController(ctx *pulumi.Context, name string, args *ElbControllerArgs,
 		return nil, err
 	}
 
-	cfg := config.New(ctx, "")
-	account := cfg.GetSecret("account")
-
 	// https://docs.aws.amazon.com/es_es/eks/latest/userguide/aws-load-balancer-controller.html
-	elbControllerRole, err := iam.NewRole(ctx, fmt.Sprintf("%s-controllerRole", name), &iam.RoleArgs{
+	elbControllerRole, err := cluster.NewServiceAccountRole(ctx, fmt.Sprintf("%s-controllerRole", name), &cluster.ServiceAccountRoleArgs{
+		IdentityMode:           args.IdentityMode,
+		ClusterName:            args.ClusterName,
+		IssuerUrlWithoutPrefix: args.IssuerUrlWithoutPrefix,
+		ServiceAccount:         "kube-system:aws-load-balancer-controller",
 		InlinePolicies: iam.RoleInlinePolicyArray{
 			iam.RoleInlinePolicyArgs{
 				Name: pulumi.StringPtr("load-balancer-controller-policy-additional"),
@@ -327,24 +331,6 @@ func NewElbController(ctx *pulumi.Context, name string, args *ElbControllerArgs,
 }`),
 			},
 		},
-		AssumeRolePolicy: pulumi.Sprintf(`{
-    "Version": "2012-10-17",
-    "Statement": [
-        {
-            "Effect": "Allow",
-            "Principal": {
-                "Federated": "arn:aws:iam::%s:oidc-provider/%s"
-            },
-            "Action": "sts:AssumeRoleWithWebIdentity",
-            "Condition": {
-                "StringEquals": {
-                    "%s:aud": "sts.amazonaws.com",
-                    "%s:sub": "system:serviceaccount:kube-system:aws-load-balancer-controller"
-                }
-            }
-        }
-    ]
-}`, account, args.IssuerUrlWithoutPrefix, args.IssuerUrlWithoutPrefix, args.IssuerUrlWithoutPrefix),
 	}, pulumi.Parent(componentResource))
 
 	if err != nil {
@@ -357,14 +343,11 @@ func NewElbController(ctx *pulumi.Context, name string, args *ElbControllerArgs,
 				"app.kubernetes.io/component": pulumi.String("controller"),
 				"app.kubernetes.io/name":      pulumi.String("aws-load-balancer-controller"),
 			},
-			Annotations: pulumi.StringMap{
-				"eks.amazonaws.com/role-arn":               pulumi.Sprintf("%s", &elbControllerRole.Arn),
-				"eks.amazonaws.com/sts-regional-endpoints": pulumi.String("true"),
-			},
-			Name:      pulumi.StringPtr("aws-load-balancer-controller"),
-			Namespace: pulumi.StringPtr("kube-system"),
+			Annotations: cluster.ServiceAccountAnnotations(args.IdentityMode, elbControllerRole.Role.Arn),
+			Name:        pulumi.StringPtr("aws-load-balancer-controller"),
+			Namespace:   pulumi.StringPtr("kube-system"),
 		},
-	}, pulumi.DependsOn([]pulumi.Resource{elbControllerRole}), pulumi.Parent(componentResource))
+	}, pulumi.DependsOn(elbControllerRole.Ready()), pulumi.Parent(componentResource))
 
 	args.ClusterName.ToStringOutput().ApplyT(func(name string) error { //name is irrelevant. We want apply futures/monad
 		stdout, stderr := exec.Command("./elb-addon-install.sh", name).Output()
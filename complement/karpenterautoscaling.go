@@ -6,6 +6,8 @@ package complement
 import (
 	"fmt"
 
+	ekscluster "k8s-cluster-own/cluster"
+
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/cloudwatch"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
@@ -23,8 +25,13 @@ type KarpenterAutoScalingArgs struct {
 	ClusterId              pulumi.StringInput
 	IssuerUrlWithoutPrefix pulumi.StringInput
 	Subnets                pulumi.StringArrayInput
+	// ekscluster.IdentityModePodIdentity falls back to IRSA, see karpenterVersion
+	IdentityMode string
 }
 
+// exported for the helm install, its aws sdk predates Pod Identity
+const karpenterVersion = "v0.31.0"
+
 func NewKarpenterAutoScaling(ctx *pulumi.Context, name string, args *KarpenterAutoScalingArgs, opts ...pulumi.ResourceOption) (*KarpenterAutoScaling, error) {
 	componentResource := &KarpenterAutoScaling{}
 
@@ -32,8 +39,13 @@ func NewKarpenterAutoScaling(ctx *pulumi.Context, name string, args *KarpenterAu
 		args = &KarpenterAutoScalingArgs{}
 	}
 
+	err := ekscluster.ValidateIdentityMode(args.IdentityMode)
+	if err != nil {
+		return nil, fmt.Errorf("karpenter %q: %w", name, err)
+	}
+
 	// <package>:<module>:<type>
-	err := ctx.RegisterComponentResource("k8s-cluster:addon:KarpenterAutoScaling", name, componentResource, opts...)
+	err = ctx.RegisterComponentResource("k8s-cluster:addon:KarpenterAutoScaling", name, componentResource, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -414,28 +426,15 @@ func NewKarpenterAutoScaling(ctx *pulumi.Context, name string, args *KarpenterAu
 		return nil, err
 	}
 
-	trustedpolicy := pulumi.Sprintf(`{
-				    "Version": "2012-10-17",
-				    "Statement": [
-				        {
-				            "Effect": "Allow",
-				            "Principal": {
-				                "Federated": "arn:aws:iam::%s:oidc-provider/%s"
-				            },
-				            "Action": "sts:AssumeRoleWithWebIdentity",
-				            "Condition": {
-				                "StringEquals": {
-				                    "%s:aud": "sts.amazonaws.com",
-				                    "%s:sub": "system:serviceaccount:karpenter:karpenter"
-				                }
-				            }
-				        }
-				    ]
-				}`, account, args.IssuerUrlWithoutPrefix, args.IssuerUrlWithoutPrefix, args.IssuerUrlWithoutPrefix)
-	//
-	KarpenterControllerRole, err := iam.NewRole(ctx, fmt.Sprintf("%s-KarpenterControllerRole", name), &iam.RoleArgs{
-		AssumeRolePolicy:  trustedpolicy,
-		ManagedPolicyArns: pulumi.StringArray{KarpenterControllerPolicy.Arn},
+	if args.IdentityMode == ekscluster.IdentityModePodIdentity {
+		ctx.Log.Warn(fmt.Sprintf("karpenter %s can't use Pod Identity, %q keeps IRSA", karpenterVersion, name), nil)
+	}
+
+	KarpenterControllerRole, err := ekscluster.NewServiceAccountRole(ctx, fmt.Sprintf("%s-KarpenterControllerRole", name), &ekscluster.ServiceAccountRoleArgs{
+		IdentityMode:           ekscluster.IdentityModeIrsa,
+		IssuerUrlWithoutPrefix: args.IssuerUrlWithoutPrefix,
+		ServiceAccount:         "karpenter:karpenter",
+		ManagedPolicyArns:      pulumi.StringArray{KarpenterControllerPolicy.Arn},
 	}, pulumi.Parent(componentResource))
 
 	if err != nil {
@@ -456,7 +455,7 @@ func NewKarpenterAutoScaling(ctx *pulumi.Context, name string, args *KarpenterAu
 	// _, err = corev1.NewServiceAccount(ctx, "AllowKarpenterToUseEc2", &corev1.ServiceAccountArgs{
 	// 	Metadata: metav1.ObjectMetaArgs{
 	// 		Annotations: pulumi.StringMap{
-	// 			"eks.amazonaws.com/role-arn":               pulumi.Sprintf("%s", KarpenterControllerRole.Arn),
+	// 			"eks.amazonaws.com/role-arn":               pulumi.Sprintf("%s", KarpenterControllerRole.Role.Arn),
 	// 			"eks.amazonaws.com/sts-regional-endpoints": pulumi.String("true"),
 	// 		},
 	// 		Name:      pulumi.StringPtr("karpenter"),
@@ -477,9 +476,9 @@ func NewKarpenterAutoScaling(ctx *pulumi.Context, name string, args *KarpenterAu
 	//   - system:bootstrappers
 	//   - system:nodes
 
-	ctx.Export("KarpenterControllerRoleArn", KarpenterControllerRole.Arn)
+	ctx.Export("KarpenterControllerRoleArn", KarpenterControllerRole.Role.Arn)
 	ctx.Export("KarpenterNodeRoleArn", karpenterNodeRole.Arn)
-	ctx.Export("KarpenterVersion", pulumi.String("v0.31.0"))
+	ctx.Export("KarpenterVersion", pulumi.String(karpenterVersion))
 	ctx.Export("KarpenterQueueName", InterruptionQueue.Name)
 	ctx.Export("KarpenterNodeInstanceProfileName", karpenterNodeInstanceProfile.Name)
 
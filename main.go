@@ -1,21 +1,21 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 
 	// "k8s-cluster/role"
 	"k8s-cluster-own/cluster"
 	"k8s-cluster-own/complement"
+	"k8s-cluster-own/network"
 	"k8s-cluster-own/nodegroup"
 
-	// endpoints "k8s-cluster-own/service-endpoints"
-
-	// endpoints "k8s-cluster-own/service-endpoints"
-
-	"fmt"
+	endpoints "k8s-cluster-own/service-endpoints"
 
 	"k8s-cluster-own/addon"
 
-	"github.com/pulumi/pulumi-aws/sdk/v5/go/aws/eks"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 )
@@ -27,111 +27,381 @@ func main() {
 		org := cfg.Require("org")
 		stack := cfg.Require("stack")
 
-		networkRef, err := pulumi.NewStackReference(ctx, fmt.Sprintf("%s/k8s-network/%s", org, stack), &pulumi.StackReferenceArgs{})
+		clusterName := "principal-cluster"
+
+		// buckets mounted as volumes, the driver role only reaches these
+		var s3MountpointBuckets []addon.S3MountpointBucket
+		err := cfg.GetObject("s3MountpointBuckets", &s3MountpointBuckets)
+		if err != nil {
+			return fmt.Errorf("s3MountpointBuckets: %w", err)
+		}
+
+		partition, err := aws.GetPartition(ctx, nil)
 		if err != nil {
 			return err
 		}
 
-		allsubnets := networkRef.GetOutput(pulumi.String("Subnets")).AsStringArrayOutput()
-		privateSubnets := networkRef.GetOutput(pulumi.String("PrivateSubnetIds")).AsStringArrayOutput()
-		// publicSubnets:= networkRef.GetOutput(pulumi.String("PublicSubnetIds")).AsStringArrayOutput()
-		// privateRouteTableIds := networkRef.GetOutput(pulumi.String("PrivateRouteTableIds")).AsStringArrayOutput()
-		vpcId := networkRef.GetOutput(pulumi.String("VpcId")).AsStringOutput()
+		// the restricted s3 gateway policy must still let the Mountpoint driver reach its buckets
+		var s3BucketArns []string
+		for _, bucket := range s3MountpointBuckets {
+			s3BucketArns = append(s3BucketArns, bucket.BucketArn(partition.Partition))
+		}
+
+		vpcEndpointsArgs := &endpoints.VpcEndpointsArgs{
+			InterfaceEndpointServices: []string{"ecr.api", "ecr.dkr", "sts", "ssm", "ec2messages", "ssmmessages", "ec2"},
+			GatewayEndpointServices:   []string{"s3"},
+			CreateSecurityGroup:       true,
+			RestrictAccess:            true,
+			OrganizationId:            cfg.Get("organizationId"),
+			S3BucketArns:              s3BucketArns,
+			// eks-private for subnets without NAT
+			Preset: cfg.Get("vpcEndpointsPreset"),
+		}
+
+		// a private-only API endpoint or a VPC without NAT needs nodes to bootstrap through the endpoints
+		vpcEndpointsConfigured := false
+		if cfg.GetBool("vpcEndpoints") {
+			vpcEndpointsConfigured, err = vpcEndpointsArgs.CoversNodes()
+			if err != nil {
+				return err
+			}
+		}
+
+		// own VPC with the clusterVpc config object (throwaway clusters), otherwise
+		// the k8s-network stack outputs or the "network" config object
+		var clusterNetwork *network.Network
+		var networkOutputs *network.NetworkOutputs
+		var clusterVpc *network.ClusterVpc
+
+		if cfg.Get("clusterVpc") != "" {
+			var clusterVpcArgs network.ClusterVpcArgs
+			cfg.RequireObject("clusterVpc", &clusterVpcArgs)
+			clusterVpcArgs.ClusterName = clusterName
+			clusterVpcArgs.VpcEndpointsConfigured = vpcEndpointsConfigured
+
+			clusterVpc, err = network.NewClusterVpc(ctx, "cluster-vpc", &clusterVpcArgs)
+			if err != nil {
+				return err
+			}
+			clusterNetwork = clusterVpc.Network()
+		} else {
+			networkOutputs, err = network.LoadNetworkOutputs(ctx, org, stack)
+			if err != nil {
+				return err
+			}
+			clusterNetwork = networkOutputs.Network()
+		}
+
+		allsubnets := clusterNetwork.SubnetIds
+		privateSubnets := clusterNetwork.PrivateSubnetIds
+		publicSubnets := clusterNetwork.PublicSubnetIds
+		privateRouteTableIds := clusterNetwork.PrivateRouteTableIds
+		vpcId := clusterNetwork.VpcId
+
+		vpcEndpointsArgs.VpcId = vpcId
+		vpcEndpointsArgs.SubnetIds = privateSubnets
+		vpcEndpointsArgs.RouteTableIds = privateRouteTableIds
+
+		var publicAccessCidrs []string
+		err = cfg.GetObject("publicAccessCidrs", &publicAccessCidrs)
+		if err != nil {
+			return fmt.Errorf("publicAccessCidrs: %w", err)
+		}
+
+		// kubectl and pulumi reach a private-only API endpoint through it
+		var bastionSecurityGroupId pulumi.StringInput
+		if groupId := cfg.Get("bastionSecurityGroupId"); groupId != "" {
+			bastionSecurityGroupId = pulumi.String(groupId)
+		}
+
+		var secretsKmsKeyArn pulumi.StringInput
+		if keyArn := cfg.Get("secretsKmsKeyArn"); keyArn != "" {
+			secretsKmsKeyArn = pulumi.String(keyArn)
+		}
+
+		// its key policy has to let logs.<region>.amazonaws.com use it
+		var logKmsKeyArn pulumi.StringInput
+		if keyArn := cfg.Get("controlPlaneLogKmsKeyArn"); keyArn != "" {
+			logKmsKeyArn = pulumi.String(keyArn)
+		}
 
-		principalCluster, err := cluster.NewPrincipalCluster(ctx, "principal-cluster", &cluster.PrincipalClusterArgs{
-			SubnetsIds: allsubnets,
-			VpcId:      vpcId,
+		var logTypes []string
+		err = cfg.GetObject("controlPlaneLogTypes", &logTypes)
+		if err != nil {
+			return fmt.Errorf("controlPlaneLogTypes: %w", err)
+		}
+
+		principalCluster, err := cluster.NewPrincipalCluster(ctx, clusterName, &cluster.PrincipalClusterArgs{
+			SubnetsIds:             allsubnets,
+			VpcId:                  vpcId,
+			PrivateSubnetsIds:      privateSubnets,
+			PublicSubnetsIds:       publicSubnets,
+			Version:                cfg.Get("kubernetesVersion"),
+			EndpointAccess:         cfg.Get("endpointAccess"),
+			PublicAccessCidrs:      publicAccessCidrs,
+			VpcEndpointsConfigured: vpcEndpointsConfigured,
+			BastionSecurityGroupId: bastionSecurityGroupId,
+			SecretsEncryption:      cfg.GetBool("secretsEncryption"),
+			SecretsKmsKeyArn:       secretsKmsKeyArn,
+			LogTypes:               logTypes,
+			LogRetentionDays:       cfg.GetInt("controlPlaneLogRetentionDays"),
+			LogKmsKeyArn:           logKmsKeyArn,
+			IpFamily:               cfg.Get("ipFamily"),
+			ServiceIpv4Cidr:        cfg.Get("serviceIpv4Cidr"),
+			PodSecurityGroups:      cfg.GetBool("podSecurityGroups"),
 		})
 
 		if err != nil {
 			return err
 		}
 
-		_, err = nodegroup.NewOpenNodeGroup(ctx, "t2-micro-amd64", &nodegroup.OpenNodeGroupArgs{
-			NodeGroupArgs: eks.NodeGroupArgs{
-				// AmiType:      pulumi.StringPtr("AL2_ARM_64"),
-				ClusterName:  principalCluster.Cluster.Name,
-				CapacityType: pulumi.StringPtr("ON_DEMAND"),
-				DiskSize:     pulumi.IntPtr(5),
-				ScalingConfig: eks.NodeGroupScalingConfigArgs{
-					MinSize:     pulumi.Int(2),
-					DesiredSize: pulumi.Int(2),
-					MaxSize:     pulumi.Int(6),
-				},
-				Labels: pulumi.ToStringMap(map[string]string{
-					"arch": "arm64",
-				}),
-				InstanceTypes: pulumi.ToStringArray([]string{"t2.micro"}),
-				SubnetIds:     privateSubnets,
-				Tags: pulumi.StringMap(map[string]pulumi.StringInput{
-					"karpenter.sh/discovery": principalCluster.Cluster.Name,
-				}),
-			},
-		})
+		// years of audit logs in S3/Glacier instead of CloudWatch
+		if cfg.GetBool("auditArchive") {
+			_, err = cluster.NewAuditArchive(ctx, "audit-archive", &cluster.AuditArchiveArgs{
+				Cluster: principalCluster,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// plain ids so every tag is known at preview and keyed by subnet id, a ClusterVpc tags its own subnets
+		if networkOutputs != nil {
+			_, err = cluster.NewSubnetTags(ctx, "subnet-tags", &cluster.SubnetTagsArgs{
+				Cluster:           principalCluster,
+				PublicSubnetIds:   networkOutputs.PublicSubnetIds,
+				PrivateSubnetIds:  networkOutputs.PrivateSubnetIds,
+				LegacySubnetOrder: networkOutputs.SubnetIds,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		nodeGroupConfigs, err := nodegroup.LoadNodeGroupConfigs(ctx)
 		if err != nil {
 			return err
 		}
 
-		// _, err = nodegroup.NewOpenNodeGroup(ctx, "t4g-small-arm64", &nodegroup.OpenNodeGroupArgs{
-		// 	NodeGroupArgs: eks.NodeGroupArgs{
-		// 		AmiType:      pulumi.StringPtr("AL2_ARM_64"),
-		// 		ClusterName:  principalCluster.Cluster.Name,
-		// 		CapacityType: pulumi.StringPtr("ON_DEMAND"),
-		// 		DiskSize:     pulumi.IntPtr(5),
-		// 		ScalingConfig: eks.NodeGroupScalingConfigArgs{
-		// 			MinSize:     pulumi.Int(2),
-		// 			DesiredSize: pulumi.Int(3),
-		// 			MaxSize:     pulumi.Int(6),
-		// 		},
-		// 		Labels: pulumi.ToStringMap(map[string]string{
-		// 			"arch": "arm64",
-		// 		}),
-		// 		InstanceTypes: pulumi.ToStringArray([]string{"t4g.small"}),
-		// 		SubnetIds:     privateSubnets,
-		// 	},
-		// })
-		// if err != nil {
-		// 	return err
-		// }
-		//
-		amdGroup, err := nodegroup.NewOpenNodeGroup(ctx, "t2-medium-amd64", &nodegroup.OpenNodeGroupArgs{
-			NodeGroupArgs: eks.NodeGroupArgs{
-				ClusterName:  principalCluster.Cluster.Name,
-				CapacityType: pulumi.StringPtr("SPOT"),
-				DiskSize:     pulumi.IntPtr(5),
-				// NodeRoleArn:  injected,
-				ScalingConfig: eks.NodeGroupScalingConfigArgs{
-					MinSize:     pulumi.Int(2),
-					DesiredSize: pulumi.Int(2),
-					MaxSize:     pulumi.Int(6),
-				},
-				Labels: pulumi.ToStringMap(map[string]string{
-					"arch": "amd64",
-				}),
-				InstanceTypes: pulumi.ToStringArray([]string{"t2.medium"}),
-				SubnetIds:     privateSubnets,
-				Tags: pulumi.StringMap(map[string]pulumi.StringInput{
-					"karpenter.sh/discovery": principalCluster.Cluster.Name,
-				}),
-			},
+		// Upgrades go control plane -> managed addons -> node groups, each step reads the cluster Version output.
+		// Addons running as DaemonSets don't need nodes and go in beforeCompute. The ones that schedule
+		// Deployments (coredns, ebs...) can't become ACTIVE without nodes, they only follow the node groups on the first deployment
+		var beforeCompute []pulumi.Resource
+
+		// nodes need the NAT routes to bootstrap
+		if clusterVpc != nil {
+			beforeCompute = append(beforeCompute, clusterVpc)
+		}
+
+		// irsa (default) or pod-identity, the pod identity agent below serves the associations
+		identityMode := cfg.Get("identityMode")
+
+		if cfg.GetBool("vpcEndpoints") {
+			// preview warns when one of the components deployed below misses an endpoint
+			vpcEndpointsArgs.Components = []string{
+				endpoints.ComponentNodes,
+				endpoints.ComponentEbsController,
+				endpoints.ComponentElbController,
+				endpoints.ComponentKarpenter,
+				endpoints.ComponentClusterAutoscaling,
+			}
+			if cfg.GetBool("efs") {
+				vpcEndpointsArgs.Components = append(vpcEndpointsArgs.Components, endpoints.ComponentEfsController)
+			}
+			if len(s3MountpointBuckets) > 0 {
+				vpcEndpointsArgs.Components = append(vpcEndpointsArgs.Components, endpoints.ComponentS3Mountpoint)
+			}
+			// the agent exchanges the association for credentials through eks-auth
+			if identityMode == cluster.IdentityModePodIdentity {
+				vpcEndpointsArgs.Components = append(vpcEndpointsArgs.Components, endpoints.ComponentPodIdentity)
+			}
+
+			vpcEndpoints, err := endpoints.NewVpcEndpoints(ctx, "useful-vpc-endpoint-services", vpcEndpointsArgs, pulumi.DependsOn([]pulumi.Resource{principalCluster}))
+			if err != nil {
+				return err
+			}
+			// without NAT the nodes bootstrap through ecr, sts and ec2 endpoints
+			beforeCompute = append(beforeCompute, vpcEndpoints)
+		}
+
+		// kube-proxy and the pod identity agent follow the cluster version instead of staying at the creation one
+		var kubeProxyArgs addon.KubeProxyArgs
+		err = getStrictObject(cfg, "kubeProxy", &kubeProxyArgs)
+		if err != nil {
+			return err
+		}
+		kubeProxyArgs.ClusterName = principalCluster.Cluster.Name
+		kubeProxyArgs.KubernetesVersion = principalCluster.Version
+
+		kubeProxy, err := addon.NewKubeProxy(ctx, "kube-proxy", &kubeProxyArgs)
+		if err != nil {
+			return err
+		}
+		beforeCompute = append(beforeCompute, kubeProxy)
+
+		podIdentityAgent, err := addon.NewPodIdentityAgent(ctx, "pod-identity-agent", &addon.PodIdentityAgentArgs{
+			ClusterName:       principalCluster.Cluster.Name,
+			KubernetesVersion: principalCluster.Version,
 		})
 		if err != nil {
 			return err
 		}
+		beforeCompute = append(beforeCompute, podIdentityAgent)
 
-		_, err = addon.NewEbsController(ctx, "ebs-controller", &addon.EbsControllerArgs{
+		// network policies, prefix delegation... as addon configuration values
+		var vpcCniConfig addon.VpcCniConfig
+		err = getStrictObject(cfg, "vpcCni", &vpcCniConfig)
+		if err != nil {
+			return err
+		}
+		if principalCluster.PodSecurityGroups {
+			vpcCniConfig.PodEni = true
+		}
+
+		vpcCni, err := addon.NewVpcCni(ctx, "vpc-cni", &addon.VpcCniArgs{
 			ClusterName:            principalCluster.Cluster.Name,
 			IssuerUrlWithoutPrefix: principalCluster.IssuerUrlWithoutPrefix,
-		}, pulumi.DependsOn([]pulumi.Resource{amdGroup}))
+			KubernetesVersion:      principalCluster.Version,
+			IpFamily:               principalCluster.IpFamily,
+			Config:                 vpcCniConfig,
+			IdentityMode:           identityMode,
+			PodSecurityGroupIds:    pulumi.StringArray{principalCluster.NodeSecurityGroup.ID()},
+		})
+		if err != nil {
+			return err
+		}
+		beforeCompute = append(beforeCompute, vpcCni)
 
+		var coreDnsArgs addon.CoreDnsArgs
+		err = getStrictObject(cfg, "coreDns", &coreDnsArgs)
 		if err != nil {
 			return err
 		}
+		coreDnsArgs.ClusterName = principalCluster.Cluster.Name
+		coreDnsArgs.KubernetesVersion = principalCluster.Version
+
+		// Karpenter and CoreDNS on Fargate, so the cluster never waits for nodes Karpenter has to launch
+		var systemFargateProfile []pulumi.Resource
+		if cfg.GetBool("systemFargateProfile") {
+			coreDnsArgs.ComputeType = addon.CoreDnsComputeTypeFargate
+
+			fargateProfile, err := nodegroup.NewFargateProfile(ctx, "system", &nodegroup.FargateProfileArgs{
+				Cluster:   principalCluster,
+				Selectors: nodegroup.SystemFargateSelectors,
+			})
+			if err != nil {
+				return err
+			}
+			systemFargateProfile = append(systemFargateProfile, fargateProfile)
+		}
+
+		var ebsKmsKeyArn pulumi.StringInput
+		if keyArn := cfg.Get("ebsKmsKeyArn"); keyArn != "" {
+			ebsKmsKeyArn = pulumi.String(keyArn)
+		}
+
+		// the addons whose pods need somewhere to schedule
+		newWorkloadAddons := func(dependsOn []pulumi.Resource) ([]pulumi.Resource, error) {
+			var workloadAddons []pulumi.Resource
+
+			coreDns, err := addon.NewCoreDns(ctx, "coredns", &coreDnsArgs, pulumi.DependsOn(append(systemFargateProfile, dependsOn...)))
+			if err != nil {
+				return nil, err
+			}
+			workloadAddons = append(workloadAddons, coreDns)
+
+			ebsController, err := addon.NewEbsController(ctx, "ebs-controller", &addon.EbsControllerArgs{
+				ClusterName:            principalCluster.Cluster.Name,
+				IssuerUrlWithoutPrefix: principalCluster.IssuerUrlWithoutPrefix,
+				KubernetesVersion:      principalCluster.Version,
+				KmsKeyArn:              ebsKmsKeyArn,
+				ReclaimPolicy:          cfg.Get("ebsReclaimPolicy"),
+				Io2StorageClass:        cfg.GetBool("ebsIo2StorageClass"),
+				Snapshots:              cfg.GetBool("ebsSnapshots"),
+				IdentityMode:           identityMode,
+			}, pulumi.DependsOn(dependsOn))
+			if err != nil {
+				return nil, err
+			}
+			workloadAddons = append(workloadAddons, ebsController)
+
+			// ReadWriteMany volumes
+			if cfg.GetBool("efs") {
+				efsController, err := addon.NewEfsController(ctx, "efs-controller", &addon.EfsControllerArgs{
+					ClusterName:            principalCluster.Cluster.Name,
+					IssuerUrlWithoutPrefix: principalCluster.IssuerUrlWithoutPrefix,
+					KubernetesVersion:      principalCluster.Version,
+					VpcId:                  vpcId,
+					Subnets:                clusterNetwork.PrivateSubnets,
+					NodeSecurityGroupId:    principalCluster.NodeSecurityGroup.ID(),
+				}, pulumi.DependsOn(dependsOn))
+				if err != nil {
+					return nil, err
+				}
+				workloadAddons = append(workloadAddons, efsController)
+			}
+
+			if len(s3MountpointBuckets) > 0 {
+				s3Mountpoint, err := addon.NewS3Mountpoint(ctx, "s3-mountpoint", &addon.S3MountpointArgs{
+					ClusterName:            principalCluster.Cluster.Name,
+					IssuerUrlWithoutPrefix: principalCluster.IssuerUrlWithoutPrefix,
+					KubernetesVersion:      principalCluster.Version,
+					Buckets:                s3MountpointBuckets,
+				}, pulumi.DependsOn(dependsOn))
+				if err != nil {
+					return nil, err
+				}
+				workloadAddons = append(workloadAddons, s3Mountpoint)
+			}
+
+			return workloadAddons, nil
+		}
+
+		nodeGroupsArgs := &nodegroup.OpenNodeGroupsArgs{
+			Cluster: principalCluster,
+			Groups:  nodeGroupConfigs,
+			PodNetworking: nodegroup.PodNetworking{
+				CustomNetworking: vpcCniConfig.CustomNetworking,
+				PrefixDelegation: vpcCniConfig.PrefixDelegation,
+			},
+		}
+
+		var nodeGroups []*nodegroup.OpenNodeGroup
+		if principalCluster.Deployed {
+			workloadAddons, err := newWorkloadAddons(nil)
+			if err != nil {
+				return err
+			}
+
+			nodeGroups, err = nodegroup.NewOpenNodeGroups(ctx, nodeGroupsArgs, pulumi.DependsOn(append(beforeCompute, workloadAddons...)))
+			if err != nil {
+				return err
+			}
+		} else {
+			nodeGroups, err = nodegroup.NewOpenNodeGroups(ctx, nodeGroupsArgs, pulumi.DependsOn(beforeCompute))
+			if err != nil {
+				return err
+			}
+		}
+
+		// the complements need somewhere to schedule their pods
+		var nodeGroupResources []pulumi.Resource
+		for _, nodeGroup := range nodeGroups {
+			nodeGroupResources = append(nodeGroupResources, nodeGroup)
+		}
+
+		if !principalCluster.Deployed {
+			_, err = newWorkloadAddons(nodeGroupResources)
+			if err != nil {
+				return err
+			}
+		}
 
 		_, err = complement.NewElbController(ctx, "elb-controller", &complement.ElbControllerArgs{
 			IssuerUrlWithoutPrefix: principalCluster.IssuerUrlWithoutPrefix,
 			ClusterName:            principalCluster.Cluster.Name,
-		}, pulumi.DependsOn([]pulumi.Resource{amdGroup}))
+			IdentityMode:           identityMode,
+		}, pulumi.DependsOn(nodeGroupResources))
 
 		if err != nil {
 			return err
@@ -140,7 +410,7 @@ func main() {
 		// _, err = eks.NewAddon(ctx, "kubecost", &eks.AddonArgs{
 		// 	AddonName:   pulumi.String("kubecost_kubecost"),
 		// 	ClusterName: principalCluster.Cluster.Name,
-		// }, pulumi.DependsOn([]pulumi.Resource{amdGroup}))
+		// }, pulumi.DependsOn(nodeGroupResources))
 		//
 		// if err != nil {
 		// 	return err
@@ -151,6 +421,7 @@ func main() {
 			ClusterId:              principalCluster.Cluster.ID(),
 			IssuerUrlWithoutPrefix: principalCluster.IssuerUrlWithoutPrefix,
 			Subnets:                privateSubnets,
+			IdentityMode:           identityMode,
 		})
 
 		if err != nil {
@@ -159,26 +430,28 @@ func main() {
 
 		_, err = complement.NewClusterAutoscaling(ctx, "cluster-autoscaling", &complement.ClusterAutoscalingArgs{
 			IssuerUrlWithoutPrefix: principalCluster.IssuerUrlWithoutPrefix,
+			ClusterName:            principalCluster.Cluster.Name,
+			IdentityMode:           identityMode,
 		})
 
-		// var InterfaceEndpointServices []string = []string{"ecr.api", "ecr.dkr", "sts", "ssm", "ec2messages", "ssmmessages", "ec2"}
-		// var GatewayEndpointServices []string = []string{"s3"}
-		//
-		// _, err = endpoints.NewVpcEndpoints(ctx, "useful-vpc-endpoint-services", &endpoints.VpcEndpointsArgs{
-		// 	InterfaceEndpointServices: pulumi.ToStringArray(InterfaceEndpointServices),
-		// 	GatewayEndpointServices:   pulumi.ToStringArray(GatewayEndpointServices),
-		// 	VpcId:                     vpcId,
-		// 	SubnetIds:                 privateSubnets,
-		// 	RouteTableIds:             privateRouteTableIds,
-		// 	SecurityGroupIds: pulumi.StringArray{principalCluster.Cluster.VpcConfig.ClusterSecurityGroupId().ApplyT(
-		// 		func(sgId *string) string {
-		// 			return *sgId
-		// 		}).(pulumi.StringOutput)},
-		// }, pulumi.DependsOn([]pulumi.Resource{principalCluster}))
-		// if err != nil {
-		// 	return err
-		// }
+		if err != nil {
+			return err
+		}
 
 		return nil
 	})
 }
+
+// getStrictObject is cfg.GetObject failing on unknown keys, a typo would silently keep the addon defaults
+func getStrictObject(cfg *config.Config, key string, output interface{}) error {
+	value := cfg.Get(key)
+	if value == "" {
+		return nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(output); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ClusterVpcArgs.NatGateways values
+const (
+	// private subnets only reach AWS through vpc endpoints (see the eks-private preset)
+	NatGatewaysNone = "none"
+	// one NAT in the first zone, cheap but the zone is a single point of failure
+	NatGatewaysSingle = "single"
+	NatGatewaysPerAz  = "per-az"
+)
+
+const (
+	DefaultVpcCidrBlock      = "10.0.0.0/16"
+	DefaultAvailabilityZones = 3
+	// each subnet takes 1/16 of the VPC, a /20 in a /16: privates first, publics from the 9th on
+	subnetNewBits      = 4
+	publicSubnetOffset = 8
+	// smaller subnets run out of addresses with the VPC CNI, a /24 VPC would give /28s of 11 usable IPs
+	maxSubnetPrefix = 24
+)
+
+// ClusterVpc is a self-contained network for throwaway clusters, the same shape pulumi-eks-network builds:
+// one public and one private subnet per zone, an internet gateway and NAT per NatGateways.
+// Subnets carry the cluster, elb and karpenter tags, so SubnetTags isn't needed
+type ClusterVpc struct {
+	pulumi.ResourceState
+	Vpc                  *ec2.Vpc
+	VpcId                pulumi.StringOutput
+	SubnetIds            pulumi.StringArrayOutput
+	PrivateSubnetIds     pulumi.StringArrayOutput
+	PublicSubnetIds      pulumi.StringArrayOutput
+	PrivateRouteTableIds pulumi.StringArrayOutput
+	PrivateSubnets       []*ec2.Subnet
+	// zone of each private subnet
+	privateZones []string
+}
+
+type ClusterVpcArgs struct {
+	// Name of the EKS cluster, for the subnet tags
+	ClusterName       string `json:"-"`
+	CidrBlock         string `json:"cidrBlock"`
+	AvailabilityZones int    `json:"availabilityZones"`
+	NatGateways       string `json:"natGateways"`
+	// The stack creates vpc endpoints covering the nodes, required with NatGatewaysNone
+	VpcEndpointsConfigured bool `json:"-"`
+}
+
+func (args *ClusterVpcArgs) withDefaults() {
+	if args.CidrBlock == "" {
+		args.CidrBlock = DefaultVpcCidrBlock
+	}
+	if args.AvailabilityZones == 0 {
+		args.AvailabilityZones = DefaultAvailabilityZones
+	}
+	if args.NatGateways == "" {
+		args.NatGateways = NatGatewaysSingle
+	}
+}
+
+func (args *ClusterVpcArgs) validate() error {
+	if args.ClusterName == "" {
+		return errors.New("ClusterName is required")
+	}
+
+	_, network, err := net.ParseCIDR(args.CidrBlock)
+	if err != nil || network.IP.To4() == nil {
+		return fmt.Errorf("cidrBlock %q is not an ipv4 CIDR", args.CidrBlock)
+	}
+	if ones, _ := network.Mask.Size(); ones < 16 || ones+subnetNewBits > maxSubnetPrefix {
+		return fmt.Errorf("cidrBlock %q must be between /16 and /%d, each subnet takes 1/%d of it", args.CidrBlock, maxSubnetPrefix-subnetNewBits, 1<<subnetNewBits)
+	}
+
+	if args.AvailabilityZones < MinAvailabilityZones || args.AvailabilityZones > publicSubnetOffset {
+		return fmt.Errorf("availabilityZones must be between %d and %d", MinAvailabilityZones, publicSubnetOffset)
+	}
+
+	switch args.NatGateways {
+	case NatGatewaysNone, NatGatewaysSingle, NatGatewaysPerAz:
+	default:
+		return fmt.Errorf("natGateways %q must be %s, %s or %s", args.NatGateways, NatGatewaysNone, NatGatewaysSingle, NatGatewaysPerAz)
+	}
+	if args.NatGateways == NatGatewaysNone && !args.VpcEndpointsConfigured {
+		return fmt.Errorf("natGateways %s needs vpcEndpoints covering the nodes (vpcEndpointsPreset: eks-private), nodes couldn't join the cluster", NatGatewaysNone)
+	}
+
+	return nil
+}
+
+func NewClusterVpc(ctx *pulumi.Context, name string, args *ClusterVpcArgs, opts ...pulumi.ResourceOption) (*ClusterVpc, error) {
+	componentResource := &ClusterVpc{}
+
+	if args == nil {
+		args = &ClusterVpcArgs{}
+	}
+
+	args.withDefaults()
+	err := args.validate()
+	if err != nil {
+		return nil, fmt.Errorf("cluster vpc %q: %w", name, err)
+	}
+
+	zones, err := aws.GetAvailabilityZones(ctx, &aws.GetAvailabilityZonesArgs{
+		State: pulumi.StringRef("available"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(zones.Names) < args.AvailabilityZones {
+		return nil, fmt.Errorf("cluster vpc %q: the region has %d availability zones, %d requested", name, len(zones.Names), args.AvailabilityZones)
+	}
+	zoneNames := zones.Names[:args.AvailabilityZones]
+
+	// <package>:<module>:<type>
+	err = ctx.RegisterComponentResource("my-own-cluster:network:ClusterVpc", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	vpc, err := ec2.NewVpc(ctx, fmt.Sprintf("%s-vpc", name), &ec2.VpcArgs{
+		CidrBlock:          pulumi.StringPtr(args.CidrBlock),
+		EnableDnsHostnames: pulumi.BoolPtr(true),
+		EnableDnsSupport:   pulumi.BoolPtr(true),
+		Tags: pulumi.StringMap{
+			"Name": pulumi.String(name),
+		},
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	internetGateway, err := ec2.NewInternetGateway(ctx, fmt.Sprintf("%s-internet-gateway", name), &ec2.InternetGatewayArgs{
+		VpcId: vpc.ID(),
+		Tags: pulumi.StringMap{
+			"Name": pulumi.String(name),
+		},
+	}, pulumi.Parent(vpc))
+
+	if err != nil {
+		return nil, err
+	}
+
+	publicRouteTable, err := ec2.NewRouteTable(ctx, fmt.Sprintf("%s-public-route-table", name), &ec2.RouteTableArgs{
+		VpcId: vpc.ID(),
+		Routes: ec2.RouteTableRouteArray{
+			ec2.RouteTableRouteArgs{
+				CidrBlock: pulumi.String("0.0.0.0/0"),
+				GatewayId: internetGateway.ID(),
+			},
+		},
+		Tags: pulumi.StringMap{
+			"Name": pulumi.Sprintf("%s-public", name),
+		},
+	}, pulumi.Parent(vpc))
+
+	if err != nil {
+		return nil, err
+	}
+
+	clusterTag := fmt.Sprintf("kubernetes.io/cluster/%s", args.ClusterName)
+
+	var publicSubnets, privateSubnets []*ec2.Subnet
+	var natGateways []*ec2.NatGateway
+
+	for i, zone := range zoneNames {
+		publicSubnet, err := ec2.NewSubnet(ctx, fmt.Sprintf("%s-public-%s", name, zone), &ec2.SubnetArgs{
+			VpcId:               vpc.ID(),
+			AvailabilityZone:    pulumi.StringPtr(zone),
+			CidrBlock:           pulumi.StringPtr(subnetCidr(args.CidrBlock, publicSubnetOffset+i)),
+			MapPublicIpOnLaunch: pulumi.BoolPtr(true),
+			Tags: pulumi.StringMap{
+				"Name":                   pulumi.Sprintf("%s-public-%s", name, zone),
+				clusterTag:               pulumi.String("owned"),
+				"kubernetes.io/role/elb": pulumi.String("1"),
+			},
+		}, pulumi.Parent(vpc))
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = ec2.NewRouteTableAssociation(ctx, fmt.Sprintf("%s-public-%s", name, zone), &ec2.RouteTableAssociationArgs{
+			SubnetId:     publicSubnet.ID(),
+			RouteTableId: publicRouteTable.ID(),
+		}, pulumi.Parent(publicSubnet))
+
+		if err != nil {
+			return nil, err
+		}
+
+		publicSubnets = append(publicSubnets, publicSubnet)
+
+		if args.NatGateways == NatGatewaysPerAz || (args.NatGateways == NatGatewaysSingle && i == 0) {
+			eip, err := ec2.NewEip(ctx, fmt.Sprintf("%s-nat-%s", name, zone), &ec2.EipArgs{
+				Domain: pulumi.StringPtr("vpc"),
+				Tags: pulumi.StringMap{
+					"Name": pulumi.Sprintf("%s-nat-%s", name, zone),
+				},
+			}, pulumi.Parent(publicSubnet), pulumi.DependsOn([]pulumi.Resource{internetGateway}))
+
+			if err != nil {
+				return nil, err
+			}
+
+			natGateway, err := ec2.NewNatGateway(ctx, fmt.Sprintf("%s-nat-%s", name, zone), &ec2.NatGatewayArgs{
+				AllocationId: eip.ID(),
+				SubnetId:     publicSubnet.ID(),
+				Tags: pulumi.StringMap{
+					"Name": pulumi.Sprintf("%s-%s", name, zone),
+				},
+			}, pulumi.Parent(publicSubnet))
+
+			if err != nil {
+				return nil, err
+			}
+
+			natGateways = append(natGateways, natGateway)
+		}
+	}
+
+	var privateRouteTables []*ec2.RouteTable
+
+	// a route table per zone whatever NatGateways is, changing it only changes routes
+	for i, zone := range zoneNames {
+		routes := ec2.RouteTableRouteArray{}
+		if len(natGateways) > 0 {
+			natGateway := natGateways[0]
+			if args.NatGateways == NatGatewaysPerAz {
+				natGateway = natGateways[i]
+			}
+			routes = append(routes, ec2.RouteTableRouteArgs{
+				CidrBlock:    pulumi.String("0.0.0.0/0"),
+				NatGatewayId: natGateway.ID(),
+			})
+		}
+
+		privateRouteTable, err := ec2.NewRouteTable(ctx, fmt.Sprintf("%s-private-route-table-%s", name, zone), &ec2.RouteTableArgs{
+			VpcId:  vpc.ID(),
+			Routes: routes,
+			Tags: pulumi.StringMap{
+				"Name": pulumi.Sprintf("%s-private-%s", name, zone),
+			},
+		}, pulumi.Parent(vpc))
+
+		if err != nil {
+			return nil, err
+		}
+
+		privateSubnet, err := ec2.NewSubnet(ctx, fmt.Sprintf("%s-private-%s", name, zone), &ec2.SubnetArgs{
+			VpcId:            vpc.ID(),
+			AvailabilityZone: pulumi.StringPtr(zone),
+			CidrBlock:        pulumi.StringPtr(subnetCidr(args.CidrBlock, i)),
+			Tags: pulumi.StringMap{
+				"Name":                            pulumi.Sprintf("%s-private-%s", name, zone),
+				clusterTag:                        pulumi.String("owned"),
+				"kubernetes.io/role/internal-elb": pulumi.String("1"),
+				"karpenter.sh/discovery":          pulumi.String(args.ClusterName),
+			},
+		}, pulumi.Parent(vpc))
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = ec2.NewRouteTableAssociation(ctx, fmt.Sprintf("%s-private-%s", name, zone), &ec2.RouteTableAssociationArgs{
+			SubnetId:     privateSubnet.ID(),
+			RouteTableId: privateRouteTable.ID(),
+		}, pulumi.Parent(privateSubnet))
+
+		if err != nil {
+			return nil, err
+		}
+
+		privateSubnets = append(privateSubnets, privateSubnet)
+		privateRouteTables = append(privateRouteTables, privateRouteTable)
+	}
+
+	componentResource.Vpc = vpc
+	componentResource.PrivateSubnets = privateSubnets
+	componentResource.privateZones = zoneNames
+	componentResource.VpcId = vpc.ID().ToStringOutput()
+	componentResource.PrivateSubnetIds = subnetIds(privateSubnets)
+	componentResource.PublicSubnetIds = subnetIds(publicSubnets)
+	componentResource.SubnetIds = subnetIds(append(append([]*ec2.Subnet{}, privateSubnets...), publicSubnets...))
+
+	var routeTableIds pulumi.StringArray
+	for _, routeTable := range privateRouteTables {
+		routeTableIds = append(routeTableIds, routeTable.ID().ToStringOutput())
+	}
+	componentResource.PrivateRouteTableIds = routeTableIds.ToStringArrayOutput()
+
+	// same names as the k8s-network stack
+	ctx.Export(outputVpcId, componentResource.VpcId)
+	ctx.Export(outputSubnets, componentResource.SubnetIds)
+	ctx.Export(outputPrivateSubnetIds, componentResource.PrivateSubnetIds)
+	ctx.Export(outputPublicSubnetIds, componentResource.PublicSubnetIds)
+	ctx.Export(outputPrivateRouteTableIds, componentResource.PrivateRouteTableIds)
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
+
+// Network is the cluster view of the VPC, whether it is a ClusterVpc or the k8s-network stack
+func (c *ClusterVpc) Network() *Network {
+	var privateSubnets []Subnet
+	for i, subnet := range c.PrivateSubnets {
+		privateSubnets = append(privateSubnets, Subnet{
+			Id:               subnet.ID().ToStringOutput(),
+			AvailabilityZone: c.privateZones[i],
+		})
+	}
+
+	return &Network{
+		VpcId:                c.VpcId,
+		SubnetIds:            c.SubnetIds,
+		PrivateSubnetIds:     c.PrivateSubnetIds,
+		PublicSubnetIds:      c.PublicSubnetIds,
+		PrivateRouteTableIds: c.PrivateRouteTableIds,
+		PrivateSubnets:       privateSubnets,
+	}
+}
+
+func subnetIds(subnets []*ec2.Subnet) pulumi.StringArrayOutput {
+	var ids pulumi.StringArray
+	for _, subnet := range subnets {
+		ids = append(ids, subnet.ID().ToStringOutput())
+	}
+	return ids.ToStringArrayOutput()
+}
+
+// subnetCidr is the index-th 1/2^subnetNewBits block of the VPC, like terraform's cidrsubnet
+func subnetCidr(vpcCidr string, index int) string {
+	_, network, _ := net.ParseCIDR(vpcCidr)
+	ones, _ := network.Mask.Size()
+	prefix := ones + subnetNewBits
+
+	base := binary.BigEndian.Uint32(network.IP.To4())
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, base+uint32(index)<<(32-prefix))
+
+	return fmt.Sprintf("%s/%d", ip, prefix)
+}
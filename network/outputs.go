@@ -0,0 +1,241 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+)
+
+// NetworkOutputs is what the cluster needs from the network, the outputs of <org>/k8s-network/<stack>
+// or the same values from config. Everything is plain so it is known at preview
+type NetworkOutputs struct {
+	VpcId string `json:"vpcId"`
+	// All the subnets, in the order the network stack exports them (subnet tags were keyed by that position)
+	SubnetIds            []string `json:"subnetIds"`
+	PrivateSubnetIds     []string `json:"privateSubnetIds"`
+	PublicSubnetIds      []string `json:"publicSubnetIds"`
+	PrivateRouteTableIds []string `json:"privateRouteTableIds"`
+	// subnet id -> availability zone, filled by Validate
+	AvailabilityZones map[string]string `json:"-"`
+}
+
+// output names of the k8s-network stack
+const (
+	outputVpcId                = "VpcId"
+	outputSubnets              = "Subnets"
+	outputPrivateSubnetIds     = "PrivateSubnetIds"
+	outputPublicSubnetIds      = "PublicSubnetIds"
+	outputPrivateRouteTableIds = "PrivateRouteTableIds"
+)
+
+const MinAvailabilityZones = 2
+
+// LoadNetworkOutputs reads the network from the "network" config object when there is one,
+// otherwise from the <org>/k8s-network/<stack> stack reference, and validates it
+func LoadNetworkOutputs(ctx *pulumi.Context, org, stack string) (*NetworkOutputs, error) {
+	var outputs *NetworkOutputs
+	var err error
+
+	cfg := config.New(ctx, "")
+	var local NetworkOutputs
+	err = cfg.GetObject("network", &local)
+	if err != nil {
+		return nil, fmt.Errorf("network config: %w", err)
+	}
+
+	if local.VpcId != "" {
+		outputs = &local
+	} else {
+		outputs, err = FromStackReference(ctx, fmt.Sprintf("%s/k8s-network/%s", org, stack))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = outputs.Validate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
+
+// FromStackReference reads the network stack outputs, failing on missing or mistyped ones
+func FromStackReference(ctx *pulumi.Context, name string) (*NetworkOutputs, error) {
+	ref, err := pulumi.NewStackReference(ctx, name, &pulumi.StackReferenceArgs{})
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := &NetworkOutputs{}
+
+	outputs.VpcId, err = stackString(ref, outputVpcId)
+	if err != nil {
+		return nil, err
+	}
+	outputs.SubnetIds, err = stackStringList(ref, outputSubnets)
+	if err != nil {
+		return nil, err
+	}
+	outputs.PrivateSubnetIds, err = stackStringList(ref, outputPrivateSubnetIds)
+	if err != nil {
+		return nil, err
+	}
+	outputs.PublicSubnetIds, err = stackStringList(ref, outputPublicSubnetIds)
+	if err != nil {
+		return nil, err
+	}
+	outputs.PrivateRouteTableIds, err = stackStringList(ref, outputPrivateRouteTableIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
+
+// Validate checks the subnets are split in public and private, belong to the VPC
+// and the private ones span at least MinAvailabilityZones
+func (o *NetworkOutputs) Validate(ctx *pulumi.Context) error {
+	if o.VpcId == "" {
+		return errors.New("network: vpcId is empty")
+	}
+	if len(o.PrivateSubnetIds) == 0 {
+		return errors.New("network: there are no private subnets")
+	}
+
+	for _, subnetId := range o.PublicSubnetIds {
+		if slices.Contains(o.PrivateSubnetIds, subnetId) {
+			return fmt.Errorf("network: subnet %s is public and private", subnetId)
+		}
+	}
+
+	// config usually leaves the full list out
+	if len(o.SubnetIds) == 0 {
+		o.SubnetIds = append(append([]string{}, o.PrivateSubnetIds...), o.PublicSubnetIds...)
+	}
+	for _, subnetId := range o.SubnetIds {
+		if !slices.Contains(o.PrivateSubnetIds, subnetId) && !slices.Contains(o.PublicSubnetIds, subnetId) {
+			return fmt.Errorf("network: subnet %s is neither public nor private", subnetId)
+		}
+	}
+	for _, subnetId := range append(append([]string{}, o.PrivateSubnetIds...), o.PublicSubnetIds...) {
+		if !slices.Contains(o.SubnetIds, subnetId) {
+			return fmt.Errorf("network: subnet %s is missing from the subnet list", subnetId)
+		}
+	}
+
+	o.AvailabilityZones = map[string]string{}
+	for _, id := range o.SubnetIds {
+		subnetId := id
+		subnet, err := ec2.LookupSubnet(ctx, &ec2.LookupSubnetArgs{Id: &subnetId})
+		if err != nil {
+			return fmt.Errorf("network: subnet %s: %w", subnetId, err)
+		}
+		if subnet.VpcId != o.VpcId {
+			return fmt.Errorf("network: subnet %s belongs to %s, not to %s", subnetId, subnet.VpcId, o.VpcId)
+		}
+		o.AvailabilityZones[subnetId] = subnet.AvailabilityZone
+	}
+
+	// EKS wants subnets in two zones at least, nodes go to the private ones
+	privateZones := map[string]bool{}
+	for _, subnetId := range o.PrivateSubnetIds {
+		privateZones[o.AvailabilityZones[subnetId]] = true
+	}
+	if len(privateZones) < MinAvailabilityZones {
+		return fmt.Errorf("network: private subnets span %d availability zones, at least %d are required", len(privateZones), MinAvailabilityZones)
+	}
+
+	return nil
+}
+
+// Network is what the components take, as outputs so a ClusterVpc can provide it too
+type Network struct {
+	VpcId                pulumi.StringOutput
+	SubnetIds            pulumi.StringArrayOutput
+	PrivateSubnetIds     pulumi.StringArrayOutput
+	PublicSubnetIds      pulumi.StringArrayOutput
+	PrivateRouteTableIds pulumi.StringArrayOutput
+	// One per private subnet, for resources created per subnet (eg. EFS mount targets)
+	PrivateSubnets []Subnet
+}
+
+// Subnet is a private subnet with what is known about it at preview
+type Subnet struct {
+	Id pulumi.StringOutput
+	// The id when it is known at preview, empty for the subnets a ClusterVpc creates
+	KnownId          string
+	AvailabilityZone string
+}
+
+func (o *NetworkOutputs) Network() *Network {
+	var privateSubnets []Subnet
+	for _, subnetId := range o.PrivateSubnetIds {
+		privateSubnets = append(privateSubnets, Subnet{
+			Id:               pulumi.String(subnetId).ToStringOutput(),
+			KnownId:          subnetId,
+			AvailabilityZone: o.AvailabilityZones[subnetId],
+		})
+	}
+
+	return &Network{
+		VpcId:                pulumi.String(o.VpcId).ToStringOutput(),
+		SubnetIds:            pulumi.ToStringArray(o.SubnetIds).ToStringArrayOutput(),
+		PrivateSubnetIds:     pulumi.ToStringArray(o.PrivateSubnetIds).ToStringArrayOutput(),
+		PublicSubnetIds:      pulumi.ToStringArray(o.PublicSubnetIds).ToStringArrayOutput(),
+		PrivateRouteTableIds: pulumi.ToStringArray(o.PrivateRouteTableIds).ToStringArrayOutput(),
+		PrivateSubnets:       privateSubnets,
+	}
+}
+
+func stackValue(ref *pulumi.StackReference, name string) (interface{}, error) {
+	details, err := ref.GetOutputDetails(name)
+	if err != nil {
+		return nil, err
+	}
+	value := details.Value
+	if value == nil {
+		value = details.SecretValue
+	}
+	if value == nil {
+		return nil, fmt.Errorf("network stack has no output %q", name)
+	}
+	return value, nil
+}
+
+func stackString(ref *pulumi.StackReference, name string) (string, error) {
+	value, err := stackValue(ref, name)
+	if err != nil {
+		return "", err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("network stack output %q is %T, not a string", name, value)
+	}
+	return s, nil
+}
+
+// stackStringList reads a list output of the network stack as plain values
+func stackStringList(ref *pulumi.StackReference, name string) ([]string, error) {
+	value, err := stackValue(ref, name)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("network stack output %q is %T, not a list", name, value)
+	}
+	var list []string
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("network stack output %q has a non string item %v", name, item)
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
@@ -0,0 +1,18 @@
+package nodegroup
+
+import (
+	"fmt"
+)
+
+// checkBranchEnis fails on instance types that can't take pods with their own security groups,
+// the ones the VPC resource controller doesn't attach a trunk ENI to (non Nitro, the burstable t family...)
+// https://docs.aws.amazon.com/eks/latest/userguide/security-groups-for-pods.html
+func checkBranchEnis(instanceTypes []string) error {
+	for _, instanceType := range instanceTypes {
+		if !trunkingCompatible[instanceType] {
+			return fmt.Errorf("instance type %s has no branch ENIs for security groups for pods", instanceType)
+		}
+	}
+
+	return nil
+}
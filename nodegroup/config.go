@@ -0,0 +1,126 @@
+package nodegroup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s-cluster-own/cluster"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+)
+
+// NodeGroupConfig is one entry of the "nodeGroups" list in the stack configuration.
+// eg.
+//
+//	k8s-cluster-own:nodeGroups:
+//	  - name: t2-medium-amd64
+//	    instanceTypes: ["t2.medium"]
+//	    capacityType: SPOT
+//	    scaling: {min: 2, desired: 2, max: 6}
+//	    labels: {arch: amd64}
+//	    subnets: private
+type NodeGroupConfig struct {
+	Name          string            `json:"name"`
+	InstanceTypes []string          `json:"instanceTypes"`
+	CapacityType  string            `json:"capacityType"`
+	Scaling       NodeGroupScaling  `json:"scaling"`
+	DiskSize      int               `json:"diskSize"`
+	Labels        map[string]string `json:"labels"`
+	Taints        []NodeGroupTaint  `json:"taints"`
+	AmiType       string            `json:"amiType"`
+	Subnets       string            `json:"subnets"`
+	Version       string            `json:"version"`
+	MaxPods       int               `json:"maxPods"`
+}
+
+// Args maps the config entry onto the component arguments.
+func (c NodeGroupConfig) Args(principalCluster *cluster.PrincipalCluster) *OpenNodeGroupArgs {
+	return &OpenNodeGroupArgs{
+		Cluster:       principalCluster,
+		InstanceTypes: c.InstanceTypes,
+		CapacityType:  c.CapacityType,
+		Scaling:       c.Scaling,
+		DiskSize:      c.DiskSize,
+		Labels:        c.Labels,
+		Taints:        c.Taints,
+		AmiType:       c.AmiType,
+		Subnets:       c.Subnets,
+		Version:       c.Version,
+		MaxPods:       c.MaxPods,
+	}
+}
+
+func (c NodeGroupConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("node group name is required")
+	}
+	if err := c.Args(nil).validate(); err != nil {
+		return fmt.Errorf("node group %q: %w", c.Name, err)
+	}
+	return nil
+}
+
+// LoadNodeGroupConfigs reads and validates the required "nodeGroups" list.
+// Unknown keys are errors, a typo like instanceType would otherwise leave the default silently
+func LoadNodeGroupConfigs(ctx *pulumi.Context) ([]NodeGroupConfig, error) {
+	var groups []NodeGroupConfig
+
+	decoder := json.NewDecoder(strings.NewReader(config.New(ctx, "").Require("nodeGroups")))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&groups); err != nil {
+		return nil, fmt.Errorf("nodeGroups: %w", err)
+	}
+
+	if err := ValidateNodeGroupConfigs(groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// ValidateNodeGroupConfigs checks every group and that names don't collide, since they become resource names.
+func ValidateNodeGroupConfigs(groups []NodeGroupConfig) error {
+	seen := map[string]bool{}
+	for _, group := range groups {
+		if err := group.Validate(); err != nil {
+			return err
+		}
+		if seen[group.Name] {
+			return fmt.Errorf("node group %q is declared more than once", group.Name)
+		}
+		seen[group.Name] = true
+	}
+	return nil
+}
+
+type OpenNodeGroupsArgs struct {
+	Cluster       *cluster.PrincipalCluster
+	Groups        []NodeGroupConfig
+	PodNetworking PodNetworking
+}
+
+// NewOpenNodeGroups expands the configured node groups into OpenNodeGroup components.
+func NewOpenNodeGroups(ctx *pulumi.Context, args *OpenNodeGroupsArgs, opts ...pulumi.ResourceOption) ([]*OpenNodeGroup, error) {
+	if err := ValidateNodeGroupConfigs(args.Groups); err != nil {
+		return nil, err
+	}
+
+	var groups []*OpenNodeGroup
+
+	for _, group := range args.Groups {
+		groupArgs := group.Args(args.Cluster)
+		groupArgs.PodNetworking = args.PodNetworking
+
+		nodeGroup, err := NewOpenNodeGroup(ctx, group.Name, groupArgs, opts...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, nodeGroup)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,150 @@
+package nodegroup
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s-cluster-own/cluster"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
+)
+
+// Pods matching the selectors run on Fargate, so system components (Karpenter, CoreDNS)
+// don't depend on nodes that Karpenter itself has to launch.
+type FargateProfile struct {
+	pulumi.ResourceState
+	Profile *eks.FargateProfile
+	Role    *iam.Role
+}
+
+type FargateProfileArgs struct {
+	Cluster   *cluster.PrincipalCluster
+	Selectors []FargateSelector
+}
+
+type FargateSelector struct {
+	Namespace string
+	Labels    map[string]string
+}
+
+// SystemFargateSelectors runs Karpenter and CoreDNS on Fargate
+var SystemFargateSelectors = []FargateSelector{
+	{Namespace: "karpenter"},
+	{Namespace: "kube-system", Labels: map[string]string{"k8s-app": "kube-dns"}},
+}
+
+// EKS accepts up to 5 selectors per profile
+const maxFargateSelectors = 5
+
+func (args FargateProfileArgs) validate() error {
+	if len(args.Selectors) == 0 || len(args.Selectors) > maxFargateSelectors {
+		return fmt.Errorf("between 1 and %d selectors are required, got %d", maxFargateSelectors, len(args.Selectors))
+	}
+	for _, selector := range args.Selectors {
+		if selector.Namespace == "" {
+			return errors.New("selector namespace is required")
+		}
+	}
+	return nil
+}
+
+func NewFargateProfile(ctx *pulumi.Context, name string, args *FargateProfileArgs, opts ...pulumi.ResourceOption) (*FargateProfile, error) {
+	componentResource := &FargateProfile{}
+
+	if args == nil {
+		args = &FargateProfileArgs{}
+	}
+
+	if args.Cluster == nil {
+		return nil, fmt.Errorf("fargate profile %q: Cluster is required", name)
+	}
+
+	if err := args.validate(); err != nil {
+		return nil, fmt.Errorf("fargate profile %q: %w", name, err)
+	}
+
+	cfg := config.New(ctx, "")
+	account := cfg.GetSecret("account")
+	awscfg := config.New(ctx, "aws")
+	region := awscfg.Require("region")
+
+	// <package>:<module>:<type>
+	err := ctx.RegisterComponentResource("k8s-cluster:nodegroup:FargateProfile", name, componentResource, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterName := args.Cluster.Cluster.Name
+
+	// https://docs.aws.amazon.com/eks/latest/userguide/pod-execution-role.html
+	podExecutionRole, err := iam.NewRole(ctx, fmt.Sprintf("%s-pod-execution-role", name), &iam.RoleArgs{
+		ManagedPolicyArns: pulumi.ToStringArray([]string{"arn:aws:iam::aws:policy/AmazonEKSFargatePodExecutionRolePolicy"}),
+		AssumeRolePolicy: pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {
+        "Service": "eks-fargate-pods.amazonaws.com"
+      },
+      "Action": "sts:AssumeRole",
+      "Condition": {
+        "ArnLike": {
+          "aws:SourceArn": "arn:aws:eks:%s:%s:fargateprofile/%s/*"
+        }
+      }
+    }
+  ]
+}`, region, account, clusterName),
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	selectors := eks.FargateProfileSelectorArray{}
+	for _, selector := range args.Selectors {
+		selectors = append(selectors, eks.FargateProfileSelectorArgs{
+			Namespace: pulumi.String(selector.Namespace),
+			Labels:    pulumi.ToStringMap(selector.Labels),
+		})
+	}
+
+	// Fargate only runs pods in private subnets
+	profile, err := eks.NewFargateProfile(ctx, fmt.Sprintf("%s-fargate-profile", name), &eks.FargateProfileArgs{
+		ClusterName:         clusterName,
+		FargateProfileName:  pulumi.StringPtr(name),
+		PodExecutionRoleArn: podExecutionRole.Arn,
+		SubnetIds:           args.Cluster.PrivateSubnetsIds,
+		Selectors:           selectors,
+		Tags: pulumi.StringMap(map[string]pulumi.StringInput{
+			"karpenter.sh/discovery": clusterName,
+		}),
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	//automatically implement by eks.NewFargateProfile: EKS adds the pod execution role to aws-auth
+	// - rolearn: <pod execution role arn>
+	//   username: system:node:{{SessionName}}
+	//   groups:
+	//   - system:bootstrappers
+	//   - system:nodes
+	//   - system:node-proxier
+
+	// CoreDNS is created pinned to ec2 (eks.amazonaws.com/compute-type: ec2), the fargate scheduler ignores it until the pin is removed.
+	// The coredns addon does it with computeType Fargate (addon.CoreDnsComputeTypeFargate)
+	// https://docs.aws.amazon.com/eks/latest/userguide/fargate-getting-started.html#fargate-gs-coredns
+
+	componentResource.Profile = profile
+	componentResource.Role = podExecutionRole
+
+	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
+
+	return componentResource, nil
+}
@@ -0,0 +1,93 @@
+package nodegroup
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// PodNetworking is the VPC CNI setup the nodes run with, it changes how many pods fit on a node
+type PodNetworking struct {
+	// the primary ENI carries no pods with custom networking
+	CustomNetworking bool
+	// every secondary IP slot holds a /28 prefix
+	PrefixDelegation bool
+}
+
+// what EKS recommends even when the ENIs fit more
+const (
+	maxPodsSmallInstance = 110
+	maxPodsLargeInstance = 250
+	largeInstanceVcpus   = 30
+)
+
+// maxPods is the max-pods of the smallest instance type of the group, like max-pods-calculator.sh
+// https://github.com/awslabs/amazon-eks-ami/blob/master/files/max-pods-calculator.sh
+func maxPods(ctx *pulumi.Context, instanceTypes []string, podNetworking PodNetworking) (int, error) {
+	result := 0
+
+	for _, instanceType := range instanceTypes {
+		info, err := ec2.GetInstanceType(ctx, &ec2.GetInstanceTypeArgs{InstanceType: instanceType})
+		if err != nil {
+			return 0, fmt.Errorf("instance type %s: %w", instanceType, err)
+		}
+
+		enis := info.MaximumNetworkInterfaces
+		if podNetworking.CustomNetworking {
+			enis--
+		}
+		ipsPerEni := info.MaximumIpv4AddressesPerInterface - 1
+		if podNetworking.PrefixDelegation {
+			ipsPerEni *= 16
+		}
+
+		pods := enis*ipsPerEni + 2
+
+		limit := maxPodsSmallInstance
+		if info.DefaultVcpus >= largeInstanceVcpus {
+			limit = maxPodsLargeInstance
+		}
+		if pods > limit {
+			pods = limit
+		}
+
+		if result == 0 || pods < result {
+			result = pods
+		}
+	}
+
+	return result, nil
+}
+
+// maxPodsUserData overrides the max-pods the AMI bootstrap derives from the ENI limits, EKS merges it
+// with its own user data. AL2 reads the env file from bootstrap.sh, Bottlerocket takes TOML settings
+func maxPodsUserData(amiType string, pods int) (string, error) {
+	if strings.HasPrefix(amiType, "BOTTLEROCKET") {
+		return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("[settings.kubernetes]\nmax-pods = %d\n", pods))), nil
+	}
+	if strings.HasPrefix(amiType, "WINDOWS") || amiType == "CUSTOM" {
+		return "", fmt.Errorf("max-pods can't be set on %s nodes", amiType)
+	}
+
+	userData := fmt.Sprintf(`MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="//"
+
+--//
+Content-Type: text/x-shellscript; charset="us-ascii"
+
+#!/bin/bash
+set -ex
+cat <<-EOF > /etc/profile.d/bootstrap.sh
+export USE_MAX_PODS=false
+export KUBELET_EXTRA_ARGS="--max-pods=%d"
+EOF
+sed -i '/^set -o errexit/a\\nsource /etc/profile.d/bootstrap.sh' /etc/eks/bootstrap.sh
+
+--//--
+`, pods)
+
+	return base64.StdEncoding.EncodeToString([]byte(userData)), nil
+}
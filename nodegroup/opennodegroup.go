@@ -1,19 +1,133 @@
 package nodegroup
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
-	"github.com/pulumi/pulumi-aws/sdk/v5/go/aws/eks"
+	"k8s-cluster-own/cluster"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/eks"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/iam"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 )
 
 type OpenNodeGroup struct {
 	pulumi.ResourceState
+	NodeGroup *eks.NodeGroup
+	Role      *iam.Role
 }
 
+// OpenNodeGroupArgs only exposes what changes between groups,
+// cluster name, subnets, discovery tags and node role come from the Cluster.
 type OpenNodeGroupArgs struct {
-	NodeGroupArgs eks.NodeGroupArgs
+	Cluster       *cluster.PrincipalCluster
+	InstanceTypes []string
+	CapacityType  string // ON_DEMAND (default) or SPOT
+	Scaling       NodeGroupScaling
+	DiskSize      int // GiB, default 5
+	Labels        map[string]string
+	Taints        []NodeGroupTaint
+	AmiType       string // empty lets EKS pick from the instance types
+	Subnets       string // private (default) or public
+	Version       string // kubelet version, empty follows the control plane
+	// Added to the cluster node security group, eg. to reach a database from one workload type
+	SecurityGroupIds pulumi.StringArrayInput
+	// kubelet max-pods, 0 keeps the AMI value unless custom networking needs it lowered
+	MaxPods       int
+	PodNetworking PodNetworking
+}
+
+type NodeGroupScaling struct {
+	Min     int `json:"min"`
+	Desired int `json:"desired"`
+	Max     int `json:"max"`
+}
+
+type NodeGroupTaint struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Effect string `json:"effect"`
+}
+
+// Allowed values, see https://docs.aws.amazon.com/eks/latest/APIReference/API_Nodegroup.html
+var (
+	capacityTypes = []string{"ON_DEMAND", "SPOT"}
+	amiTypes      = []string{
+		"AL2_x86_64",
+		"AL2_x86_64_GPU",
+		"AL2_ARM_64",
+		"BOTTLEROCKET_x86_64",
+		"BOTTLEROCKET_ARM_64",
+		"BOTTLEROCKET_x86_64_NVIDIA",
+		"BOTTLEROCKET_ARM_64_NVIDIA",
+		"WINDOWS_CORE_2019_x86_64",
+		"WINDOWS_FULL_2019_x86_64",
+		"WINDOWS_CORE_2022_x86_64",
+		"WINDOWS_FULL_2022_x86_64",
+		"CUSTOM",
+	}
+	taintEffects = []string{"NO_SCHEDULE", "NO_EXECUTE", "PREFER_NO_SCHEDULE"}
+	subnetKinds  = []string{"private", "public"}
+)
+
+const (
+	defaultCapacityType = "ON_DEMAND"
+	defaultDiskSize     = 5
+	defaultSubnets      = "private"
+)
+
+// withDefaults fills the optional fields so callers only set what differs.
+func (args OpenNodeGroupArgs) withDefaults() OpenNodeGroupArgs {
+	if args.CapacityType == "" {
+		args.CapacityType = defaultCapacityType
+	}
+	if args.DiskSize == 0 {
+		args.DiskSize = defaultDiskSize
+	}
+	if args.Subnets == "" {
+		args.Subnets = defaultSubnets
+	}
+	return args
+}
+
+func (args OpenNodeGroupArgs) validate() error {
+	args = args.withDefaults()
+
+	if len(args.InstanceTypes) == 0 {
+		return errors.New("at least one instance type is required")
+	}
+	if !slices.Contains(capacityTypes, args.CapacityType) {
+		return fmt.Errorf("capacityType %q must be one of %v", args.CapacityType, capacityTypes)
+	}
+	if args.AmiType != "" && !slices.Contains(amiTypes, args.AmiType) {
+		return fmt.Errorf("amiType %q must be one of %v", args.AmiType, amiTypes)
+	}
+	if !slices.Contains(subnetKinds, args.Subnets) {
+		return fmt.Errorf("subnets %q must be one of %v", args.Subnets, subnetKinds)
+	}
+	if args.DiskSize < 1 {
+		return errors.New("diskSize must be positive")
+	}
+	if args.MaxPods < 0 {
+		return errors.New("maxPods can't be negative")
+	}
+	s := args.Scaling
+	if s.Min < 0 || s.Max < 1 || s.Min > s.Desired || s.Desired > s.Max {
+		return fmt.Errorf("scaling must satisfy 0 <= min <= desired <= max and max >= 1, got %+v", s)
+	}
+	for _, taint := range args.Taints {
+		if taint.Key == "" {
+			return errors.New("taint key is required")
+		}
+		if !slices.Contains(taintEffects, taint.Effect) {
+			return fmt.Errorf("taint effect %q must be one of %v", taint.Effect, taintEffects)
+		}
+	}
+
+	return nil
 }
 
 func NewOpenNodeGroup(ctx *pulumi.Context, name string, args *OpenNodeGroupArgs, opts ...pulumi.ResourceOption) (*OpenNodeGroup, error) {
@@ -23,19 +137,50 @@ func NewOpenNodeGroup(ctx *pulumi.Context, name string, args *OpenNodeGroupArgs,
 		args = &OpenNodeGroupArgs{}
 	}
 
+	if args.Cluster == nil {
+		return nil, fmt.Errorf("node group %q: Cluster is required", name)
+	}
+
+	if err := args.validate(); err != nil {
+		return nil, fmt.Errorf("node group %q: %w", name, err)
+	}
+
+	groupArgs := args.withDefaults()
+
+	if groupArgs.Version != "" {
+		if err := cluster.CheckKubeletSkew(args.Cluster.KubernetesVersion, groupArgs.Version); err != nil {
+			return nil, fmt.Errorf("node group %q: %w", name, err)
+		}
+	}
+
 	// <package>:<module>:<type>
 	err := ctx.RegisterComponentResource("k8s-cluster:nodegroup:OpenNodeGroup", name, componentResource, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	managedPolicyArns := []string{
+		"arn:aws:iam::aws:policy/AmazonSSMManagedInstanceCore",       // Provides ssh access to worker nodes via AWS SSM
+		"arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryReadOnly", //Provides read-only access to ECR
+		"arn:aws:iam::aws:policy/AmazonEKSWorkerNodePolicy",          // Amazon EKS worker nodes to connect to Amazon EKS Clusters
+	}
+	var inlinePolicies iam.RoleInlinePolicyArray
+
+	// Amazon VPC CNI Plugin, the managed policy only covers ipv4
+	if args.Cluster.IpFamily == cluster.IpFamilyIpv6 {
+		inlinePolicies = iam.RoleInlinePolicyArray{
+			iam.RoleInlinePolicyArgs{
+				Name:   pulumi.StringPtr("AmazonEKS_CNI_IPv6_Policy"),
+				Policy: pulumi.StringPtr(cluster.CniIpv6PolicyDocument),
+			},
+		}
+	} else {
+		managedPolicyArns = append(managedPolicyArns, "arn:aws:iam::aws:policy/AmazonEKS_CNI_Policy")
+	}
+
 	workerRole, err := iam.NewRole(ctx, fmt.Sprintf("%s-generic-groupnode-role", name), &iam.RoleArgs{
-		ManagedPolicyArns: pulumi.ToStringArray([]string{
-			"arn:aws:iam::aws:policy/AmazonSSMManagedInstanceCore",       // Provides ssh access to worker nodes via AWS SSM
-			"arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryReadOnly", //Provides read-only access to ECR
-			"arn:aws:iam::aws:policy/AmazonEKS_CNI_Policy",               // Amazon VPC CNI Plugin
-			"arn:aws:iam::aws:policy/AmazonEKSWorkerNodePolicy",          // Amazon EKS worker nodes to connect to Amazon EKS Clusters
-		}),
+		ManagedPolicyArns: pulumi.ToStringArray(managedPolicyArns),
+		InlinePolicies:    inlinePolicies,
 		AssumeRolePolicy: pulumi.String(`{
 		    "Version": "2012-10-17",
 		    "Statement": [
@@ -53,18 +198,134 @@ func NewOpenNodeGroup(ctx *pulumi.Context, name string, args *OpenNodeGroupArgs,
 		return nil, err
 	}
 
-	// ec2.NewLaunchTemplate(ctx, "GenericNodeGroupLauncTemplate", &ec2.LaunchTemplateArgs{
-	//interesting explore options!!
-	// })
-	args.NodeGroupArgs.NodeRoleArn = workerRole.Arn
+	subnets := args.Cluster.PrivateSubnetsIds
+	if groupArgs.Subnets == "public" {
+		subnets = args.Cluster.PublicSubnetsIds
+	}
+
+	var taints eks.NodeGroupTaintArray
+	for _, taint := range groupArgs.Taints {
+		taints = append(taints, eks.NodeGroupTaintArgs{
+			Key:    pulumi.String(taint.Key),
+			Value:  pulumi.StringPtr(taint.Value),
+			Effect: pulumi.String(taint.Effect),
+		})
+	}
+
+	clusterName := args.Cluster.Cluster.Name
+
+	securityGroupIds := pulumi.StringArray{args.Cluster.NodeSecurityGroup.ID()}.ToStringArrayOutput()
+	if args.SecurityGroupIds != nil {
+		securityGroupIds = pulumi.All(securityGroupIds, args.SecurityGroupIds).ApplyT(func(all []interface{}) []string {
+			return append(all[0].([]string), all[1].([]string)...)
+		}).(pulumi.StringArrayOutput)
+	}
+
+	if args.Cluster.PodSecurityGroups {
+		err = checkBranchEnis(groupArgs.InstanceTypes)
+		if err != nil {
+			return nil, fmt.Errorf("node group %q: %w", name, err)
+		}
+	}
+
+	pods := groupArgs.MaxPods
+	if pods == 0 && groupArgs.PodNetworking.CustomNetworking {
+		pods, err = maxPods(ctx, groupArgs.InstanceTypes, groupArgs.PodNetworking)
+		if err != nil {
+			return nil, fmt.Errorf("node group %q: %w", name, err)
+		}
+	}
+
+	var userData pulumi.StringPtrInput
+	if pods > 0 {
+		encoded, err := maxPodsUserData(groupArgs.AmiType, pods)
+		if err != nil {
+			return nil, fmt.Errorf("node group %q: %w", name, err)
+		}
+		userData = pulumi.StringPtr(encoded)
+	}
+
+	// with a launch template EKS no longer attaches the cluster security group, and the disk size moves here
+	launchTemplate, err := ec2.NewLaunchTemplate(ctx, fmt.Sprintf("%s-launch-template", name), &ec2.LaunchTemplateArgs{
+		UpdateDefaultVersion: pulumi.BoolPtr(true),
+		VpcSecurityGroupIds:  securityGroupIds,
+		UserData:             userData,
+		BlockDeviceMappings: ec2.LaunchTemplateBlockDeviceMappingArray{
+			ec2.LaunchTemplateBlockDeviceMappingArgs{
+				DeviceName: pulumi.StringPtr(rootDeviceName(groupArgs.AmiType)),
+				Ebs: ec2.LaunchTemplateBlockDeviceMappingEbsArgs{
+					VolumeSize:          pulumi.IntPtr(groupArgs.DiskSize),
+					VolumeType:          pulumi.StringPtr("gp3"),
+					DeleteOnTermination: pulumi.StringPtr("true"),
+				},
+			},
+		},
+		TagSpecifications: ec2.LaunchTemplateTagSpecificationArray{
+			ec2.LaunchTemplateTagSpecificationArgs{
+				ResourceType: pulumi.StringPtr("instance"),
+				Tags: pulumi.StringMap{
+					"Name": pulumi.Sprintf("%s-%s", clusterName, name),
+				},
+			},
+		},
+	}, pulumi.Parent(componentResource))
+
+	if err != nil {
+		return nil, err
+	}
+
+	nodeGroupArgs := &eks.NodeGroupArgs{
+		ClusterName:  clusterName,
+		NodeRoleArn:  workerRole.Arn,
+		CapacityType: pulumi.StringPtr(groupArgs.CapacityType),
+		LaunchTemplate: eks.NodeGroupLaunchTemplateArgs{
+			Id:      launchTemplate.ID(),
+			Version: launchTemplate.LatestVersion.ApplyT(func(v int) string { return fmt.Sprint(v) }).(pulumi.StringOutput),
+		},
+		ScalingConfig: eks.NodeGroupScalingConfigArgs{
+			MinSize:     pulumi.Int(groupArgs.Scaling.Min),
+			DesiredSize: pulumi.Int(groupArgs.Scaling.Desired),
+			MaxSize:     pulumi.Int(groupArgs.Scaling.Max),
+		},
+		Labels:        pulumi.ToStringMap(groupArgs.Labels),
+		InstanceTypes: pulumi.ToStringArray(groupArgs.InstanceTypes),
+		SubnetIds:     subnets,
+		Tags: pulumi.StringMap(map[string]pulumi.StringInput{
+			"karpenter.sh/discovery": clusterName,
+		}),
+	}
 
-	_, err = eks.NewNodeGroup(ctx, fmt.Sprintf("%s-genericGroupNode", name), &args.NodeGroupArgs, pulumi.Parent(componentResource))
+	if len(taints) > 0 {
+		nodeGroupArgs.Taints = taints
+	}
+	// following the cluster Version output the group is rolled only after the control plane is upgraded
+	if groupArgs.Version != "" {
+		nodeGroupArgs.Version = pulumi.StringPtr(groupArgs.Version)
+	} else {
+		nodeGroupArgs.Version = args.Cluster.Version.ToStringPtrOutput()
+	}
+	if groupArgs.AmiType != "" {
+		nodeGroupArgs.AmiType = pulumi.StringPtr(groupArgs.AmiType)
+	}
+
+	nodeGroup, err := eks.NewNodeGroup(ctx, fmt.Sprintf("%s-genericGroupNode", name), nodeGroupArgs, pulumi.Parent(componentResource))
 
 	if err != nil {
 		return nil, err
 	}
 
+	componentResource.NodeGroup = nodeGroup
+	componentResource.Role = workerRole
+
 	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
 
 	return componentResource, nil
 }
+
+// Bottlerocket boots from xvda and keeps containers and images on the xvdb data volume
+func rootDeviceName(amiType string) string {
+	if strings.HasPrefix(amiType, "BOTTLEROCKET") {
+		return "/dev/xvdb"
+	}
+	return "/dev/xvda"
+}
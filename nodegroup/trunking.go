@@ -0,0 +1,1329 @@
+package nodegroup
+
+// Copied from the instances with IsTrunkingCompatible in github.com/aws/amazon-vpc-resource-controller-k8s
+// v1.8.2, pkg/aws/vpc/limits.go. Refresh it by hand from a newer release, importing the module needs a newer go
+
+// trunkingCompatible are the instance types the VPC resource controller attaches a trunk ENI to,
+// security groups for pods only run on them
+var trunkingCompatible = map[string]bool{
+	"a1.2xlarge":          true,
+	"a1.4xlarge":          true,
+	"a1.large":            true,
+	"a1.medium":           true,
+	"a1.metal":            true,
+	"a1.xlarge":           true,
+	"c5.12xlarge":         true,
+	"c5.18xlarge":         true,
+	"c5.24xlarge":         true,
+	"c5.2xlarge":          true,
+	"c5.4xlarge":          true,
+	"c5.9xlarge":          true,
+	"c5.large":            true,
+	"c5.metal":            true,
+	"c5.xlarge":           true,
+	"c5a.12xlarge":        true,
+	"c5a.16xlarge":        true,
+	"c5a.24xlarge":        true,
+	"c5a.2xlarge":         true,
+	"c5a.4xlarge":         true,
+	"c5a.8xlarge":         true,
+	"c5a.large":           true,
+	"c5a.xlarge":          true,
+	"c5ad.12xlarge":       true,
+	"c5ad.16xlarge":       true,
+	"c5ad.24xlarge":       true,
+	"c5ad.2xlarge":        true,
+	"c5ad.4xlarge":        true,
+	"c5ad.8xlarge":        true,
+	"c5ad.large":          true,
+	"c5ad.xlarge":         true,
+	"c5d.12xlarge":        true,
+	"c5d.18xlarge":        true,
+	"c5d.24xlarge":        true,
+	"c5d.2xlarge":         true,
+	"c5d.4xlarge":         true,
+	"c5d.9xlarge":         true,
+	"c5d.large":           true,
+	"c5d.metal":           true,
+	"c5d.xlarge":          true,
+	"c5n.18xlarge":        true,
+	"c5n.2xlarge":         true,
+	"c5n.4xlarge":         true,
+	"c5n.9xlarge":         true,
+	"c5n.large":           true,
+	"c5n.metal":           true,
+	"c5n.xlarge":          true,
+	"c6a.12xlarge":        true,
+	"c6a.16xlarge":        true,
+	"c6a.24xlarge":        true,
+	"c6a.2xlarge":         true,
+	"c6a.32xlarge":        true,
+	"c6a.48xlarge":        true,
+	"c6a.4xlarge":         true,
+	"c6a.8xlarge":         true,
+	"c6a.large":           true,
+	"c6a.metal":           true,
+	"c6a.xlarge":          true,
+	"c6g.12xlarge":        true,
+	"c6g.16xlarge":        true,
+	"c6g.2xlarge":         true,
+	"c6g.4xlarge":         true,
+	"c6g.8xlarge":         true,
+	"c6g.large":           true,
+	"c6g.medium":          true,
+	"c6g.metal":           true,
+	"c6g.xlarge":          true,
+	"c6gd.12xlarge":       true,
+	"c6gd.16xlarge":       true,
+	"c6gd.2xlarge":        true,
+	"c6gd.4xlarge":        true,
+	"c6gd.8xlarge":        true,
+	"c6gd.large":          true,
+	"c6gd.medium":         true,
+	"c6gd.metal":          true,
+	"c6gd.xlarge":         true,
+	"c6gn.12xlarge":       true,
+	"c6gn.16xlarge":       true,
+	"c6gn.2xlarge":        true,
+	"c6gn.4xlarge":        true,
+	"c6gn.8xlarge":        true,
+	"c6gn.large":          true,
+	"c6gn.medium":         true,
+	"c6gn.xlarge":         true,
+	"c6i.12xlarge":        true,
+	"c6i.16xlarge":        true,
+	"c6i.24xlarge":        true,
+	"c6i.2xlarge":         true,
+	"c6i.32xlarge":        true,
+	"c6i.4xlarge":         true,
+	"c6i.8xlarge":         true,
+	"c6i.large":           true,
+	"c6i.metal":           true,
+	"c6i.xlarge":          true,
+	"c6id.12xlarge":       true,
+	"c6id.16xlarge":       true,
+	"c6id.24xlarge":       true,
+	"c6id.2xlarge":        true,
+	"c6id.32xlarge":       true,
+	"c6id.4xlarge":        true,
+	"c6id.8xlarge":        true,
+	"c6id.large":          true,
+	"c6id.metal":          true,
+	"c6id.xlarge":         true,
+	"c6in.12xlarge":       true,
+	"c6in.16xlarge":       true,
+	"c6in.24xlarge":       true,
+	"c6in.2xlarge":        true,
+	"c6in.32xlarge":       true,
+	"c6in.4xlarge":        true,
+	"c6in.8xlarge":        true,
+	"c6in.large":          true,
+	"c6in.metal":          true,
+	"c6in.xlarge":         true,
+	"c7a.12xlarge":        true,
+	"c7a.16xlarge":        true,
+	"c7a.24xlarge":        true,
+	"c7a.2xlarge":         true,
+	"c7a.32xlarge":        true,
+	"c7a.48xlarge":        true,
+	"c7a.4xlarge":         true,
+	"c7a.8xlarge":         true,
+	"c7a.large":           true,
+	"c7a.medium":          true,
+	"c7a.metal-48xl":      true,
+	"c7a.xlarge":          true,
+	"c7g-flex.2xlarge":    true,
+	"c7g-flex.4xlarge":    true,
+	"c7g-flex.8xlarge":    true,
+	"c7g-flex.large":      true,
+	"c7g-flex.medium":     true,
+	"c7g-flex.xlarge":     true,
+	"c7g.12xlarge":        true,
+	"c7g.16xlarge":        true,
+	"c7g.2xlarge":         true,
+	"c7g.4xlarge":         true,
+	"c7g.8xlarge":         true,
+	"c7g.large":           true,
+	"c7g.medium":          true,
+	"c7g.metal":           true,
+	"c7g.xlarge":          true,
+	"c7gd.12xlarge":       true,
+	"c7gd.16xlarge":       true,
+	"c7gd.2xlarge":        true,
+	"c7gd.4xlarge":        true,
+	"c7gd.8xlarge":        true,
+	"c7gd.large":          true,
+	"c7gd.medium":         true,
+	"c7gd.metal":          true,
+	"c7gd.xlarge":         true,
+	"c7gn.12xlarge":       true,
+	"c7gn.16xlarge":       true,
+	"c7gn.2xlarge":        true,
+	"c7gn.4xlarge":        true,
+	"c7gn.8xlarge":        true,
+	"c7gn.large":          true,
+	"c7gn.medium":         true,
+	"c7gn.metal":          true,
+	"c7gn.xlarge":         true,
+	"c7i-flex.12xlarge":   true,
+	"c7i-flex.16xlarge":   true,
+	"c7i-flex.2xlarge":    true,
+	"c7i-flex.4xlarge":    true,
+	"c7i-flex.8xlarge":    true,
+	"c7i-flex.large":      true,
+	"c7i-flex.xlarge":     true,
+	"c7i.12xlarge":        true,
+	"c7i.16xlarge":        true,
+	"c7i.24xlarge":        true,
+	"c7i.2xlarge":         true,
+	"c7i.32xlarge":        true,
+	"c7i.48xlarge":        true,
+	"c7i.4xlarge":         true,
+	"c7i.8xlarge":         true,
+	"c7i.large":           true,
+	"c7i.metal-24xl":      true,
+	"c7i.metal-48xl":      true,
+	"c7i.xlarge":          true,
+	"c8a.12xlarge":        true,
+	"c8a.16xlarge":        true,
+	"c8a.24xlarge":        true,
+	"c8a.2xlarge":         true,
+	"c8a.48xlarge":        true,
+	"c8a.4xlarge":         true,
+	"c8a.8xlarge":         true,
+	"c8a.large":           true,
+	"c8a.medium":          true,
+	"c8a.metal-24xl":      true,
+	"c8a.metal-48xl":      true,
+	"c8a.xlarge":          true,
+	"c8g-flex.12xlarge":   true,
+	"c8g-flex.16xlarge":   true,
+	"c8g-flex.2xlarge":    true,
+	"c8g-flex.4xlarge":    true,
+	"c8g-flex.8xlarge":    true,
+	"c8g-flex.large":      true,
+	"c8g-flex.medium":     true,
+	"c8g-flex.xlarge":     true,
+	"c8g.12xlarge":        true,
+	"c8g.16xlarge":        true,
+	"c8g.24xlarge":        true,
+	"c8g.2xlarge":         true,
+	"c8g.48xlarge":        true,
+	"c8g.4xlarge":         true,
+	"c8g.8xlarge":         true,
+	"c8g.large":           true,
+	"c8g.medium":          true,
+	"c8g.metal-24xl":      true,
+	"c8g.metal-48xl":      true,
+	"c8g.xlarge":          true,
+	"c8gb.12xlarge":       true,
+	"c8gb.16xlarge":       true,
+	"c8gb.24xlarge":       true,
+	"c8gb.2xlarge":        true,
+	"c8gb.48xlarge":       true,
+	"c8gb.4xlarge":        true,
+	"c8gb.8xlarge":        true,
+	"c8gb.large":          true,
+	"c8gb.medium":         true,
+	"c8gb.metal-24xl":     true,
+	"c8gb.metal-48xl":     true,
+	"c8gb.xlarge":         true,
+	"c8gd.12xlarge":       true,
+	"c8gd.16xlarge":       true,
+	"c8gd.24xlarge":       true,
+	"c8gd.2xlarge":        true,
+	"c8gd.48xlarge":       true,
+	"c8gd.4xlarge":        true,
+	"c8gd.8xlarge":        true,
+	"c8gd.large":          true,
+	"c8gd.medium":         true,
+	"c8gd.metal-24xl":     true,
+	"c8gd.metal-48xl":     true,
+	"c8gd.xlarge":         true,
+	"c8gn.12xlarge":       true,
+	"c8gn.16xlarge":       true,
+	"c8gn.24xlarge":       true,
+	"c8gn.2xlarge":        true,
+	"c8gn.48xlarge":       true,
+	"c8gn.4xlarge":        true,
+	"c8gn.8xlarge":        true,
+	"c8gn.large":          true,
+	"c8gn.medium":         true,
+	"c8gn.metal-24xl":     true,
+	"c8gn.metal-48xl":     true,
+	"c8gn.xlarge":         true,
+	"c8i-flex.12xlarge":   true,
+	"c8i-flex.16xlarge":   true,
+	"c8i-flex.2xlarge":    true,
+	"c8i-flex.4xlarge":    true,
+	"c8i-flex.8xlarge":    true,
+	"c8i-flex.large":      true,
+	"c8i-flex.xlarge":     true,
+	"c8i.12xlarge":        true,
+	"c8i.16xlarge":        true,
+	"c8i.24xlarge":        true,
+	"c8i.2xlarge":         true,
+	"c8i.32xlarge":        true,
+	"c8i.48xlarge":        true,
+	"c8i.4xlarge":         true,
+	"c8i.8xlarge":         true,
+	"c8i.96xlarge":        true,
+	"c8i.large":           true,
+	"c8i.metal-48xl":      true,
+	"c8i.metal-96xl":      true,
+	"c8i.xlarge":          true,
+	"c8ib.12xlarge":       true,
+	"c8ib.16xlarge":       true,
+	"c8ib.24xlarge":       true,
+	"c8ib.2xlarge":        true,
+	"c8ib.32xlarge":       true,
+	"c8ib.48xlarge":       true,
+	"c8ib.4xlarge":        true,
+	"c8ib.8xlarge":        true,
+	"c8ib.96xlarge":       true,
+	"c8ib.large":          true,
+	"c8ib.metal-48xl":     true,
+	"c8ib.metal-96xl":     true,
+	"c8ib.xlarge":         true,
+	"c8id.12xlarge":       true,
+	"c8id.16xlarge":       true,
+	"c8id.24xlarge":       true,
+	"c8id.2xlarge":        true,
+	"c8id.32xlarge":       true,
+	"c8id.48xlarge":       true,
+	"c8id.4xlarge":        true,
+	"c8id.8xlarge":        true,
+	"c8id.96xlarge":       true,
+	"c8id.large":          true,
+	"c8id.metal-48xl":     true,
+	"c8id.metal-96xl":     true,
+	"c8id.xlarge":         true,
+	"c8in.12xlarge":       true,
+	"c8in.16xlarge":       true,
+	"c8in.24xlarge":       true,
+	"c8in.2xlarge":        true,
+	"c8in.32xlarge":       true,
+	"c8in.48xlarge":       true,
+	"c8in.4xlarge":        true,
+	"c8in.8xlarge":        true,
+	"c8in.96xlarge":       true,
+	"c8in.large":          true,
+	"c8in.metal-48xl":     true,
+	"c8in.metal-96xl":     true,
+	"c8in.xlarge":         true,
+	"c8ine.12xlarge":      true,
+	"c8ine.2xlarge":       true,
+	"c8ine.4xlarge":       true,
+	"c8ine.8xlarge":       true,
+	"c8ine.large":         true,
+	"c8ine.xlarge":        true,
+	"c9g.12xlarge":        true,
+	"c9g.16xlarge":        true,
+	"c9g.24xlarge":        true,
+	"c9g.2xlarge":         true,
+	"c9g.48xlarge":        true,
+	"c9g.4xlarge":         true,
+	"c9g.8xlarge":         true,
+	"c9g.large":           true,
+	"c9g.medium":          true,
+	"c9g.metal-48xl":      true,
+	"c9g.xlarge":          true,
+	"c9gd.12xlarge":       true,
+	"c9gd.16xlarge":       true,
+	"c9gd.24xlarge":       true,
+	"c9gd.2xlarge":        true,
+	"c9gd.48xlarge":       true,
+	"c9gd.4xlarge":        true,
+	"c9gd.8xlarge":        true,
+	"c9gd.large":          true,
+	"c9gd.medium":         true,
+	"c9gd.metal-48xl":     true,
+	"c9gd.xlarge":         true,
+	"d3.2xlarge":          true,
+	"d3.4xlarge":          true,
+	"d3.8xlarge":          true,
+	"d3.xlarge":           true,
+	"d3en.12xlarge":       true,
+	"d3en.2xlarge":        true,
+	"d3en.4xlarge":        true,
+	"d3en.6xlarge":        true,
+	"d3en.8xlarge":        true,
+	"d3en.xlarge":         true,
+	"dl1.24xlarge":        true,
+	"dl2q.24xlarge":       true,
+	"f2.12xlarge":         true,
+	"f2.48xlarge":         true,
+	"f2.6xlarge":          true,
+	"g4ad.16xlarge":       true,
+	"g4ad.2xlarge":        true,
+	"g4ad.4xlarge":        true,
+	"g4ad.8xlarge":        true,
+	"g4ad.xlarge":         true,
+	"g4dn.12xlarge":       true,
+	"g4dn.16xlarge":       true,
+	"g4dn.2xlarge":        true,
+	"g4dn.4xlarge":        true,
+	"g4dn.8xlarge":        true,
+	"g4dn.metal":          true,
+	"g4dn.xlarge":         true,
+	"g5.12xlarge":         true,
+	"g5.16xlarge":         true,
+	"g5.24xlarge":         true,
+	"g5.2xlarge":          true,
+	"g5.48xlarge":         true,
+	"g5.4xlarge":          true,
+	"g5.8xlarge":          true,
+	"g5.xlarge":           true,
+	"g5g.16xlarge":        true,
+	"g5g.2xlarge":         true,
+	"g5g.4xlarge":         true,
+	"g5g.8xlarge":         true,
+	"g5g.metal":           true,
+	"g5g.xlarge":          true,
+	"g6.12xlarge":         true,
+	"g6.16xlarge":         true,
+	"g6.24xlarge":         true,
+	"g6.2xlarge":          true,
+	"g6.48xlarge":         true,
+	"g6.4xlarge":          true,
+	"g6.8xlarge":          true,
+	"g6.xlarge":           true,
+	"g6e.12xlarge":        true,
+	"g6e.16xlarge":        true,
+	"g6e.24xlarge":        true,
+	"g6e.2xlarge":         true,
+	"g6e.48xlarge":        true,
+	"g6e.4xlarge":         true,
+	"g6e.8xlarge":         true,
+	"g6e.xlarge":          true,
+	"g6f.2xlarge":         true,
+	"g6f.4xlarge":         true,
+	"g6f.large":           true,
+	"g6f.xlarge":          true,
+	"g7.12xlarge":         true,
+	"g7.24xlarge":         true,
+	"g7.2xlarge":          true,
+	"g7.48xlarge":         true,
+	"g7.4xlarge":          true,
+	"g7.8xlarge":          true,
+	"g7e.12xlarge":        true,
+	"g7e.24xlarge":        true,
+	"g7e.2xlarge":         true,
+	"g7e.48xlarge":        true,
+	"g7e.4xlarge":         true,
+	"g7e.8xlarge":         true,
+	"gr6.4xlarge":         true,
+	"gr6.8xlarge":         true,
+	"gr6f.4xlarge":        true,
+	"hpc7g.16xlarge":      true,
+	"hpc7g.4xlarge":       true,
+	"hpc7g.8xlarge":       true,
+	"i3.metal":            true,
+	"i3en.12xlarge":       true,
+	"i3en.24xlarge":       true,
+	"i3en.2xlarge":        true,
+	"i3en.3xlarge":        true,
+	"i3en.6xlarge":        true,
+	"i3en.large":          true,
+	"i3en.metal":          true,
+	"i3en.xlarge":         true,
+	"i4g.16xlarge":        true,
+	"i4g.2xlarge":         true,
+	"i4g.4xlarge":         true,
+	"i4g.8xlarge":         true,
+	"i4g.large":           true,
+	"i4g.xlarge":          true,
+	"i4i.12xlarge":        true,
+	"i4i.16xlarge":        true,
+	"i4i.24xlarge":        true,
+	"i4i.2xlarge":         true,
+	"i4i.32xlarge":        true,
+	"i4i.4xlarge":         true,
+	"i4i.8xlarge":         true,
+	"i4i.large":           true,
+	"i4i.metal":           true,
+	"i4i.xlarge":          true,
+	"i7i.12xlarge":        true,
+	"i7i.16xlarge":        true,
+	"i7i.24xlarge":        true,
+	"i7i.2xlarge":         true,
+	"i7i.48xlarge":        true,
+	"i7i.4xlarge":         true,
+	"i7i.8xlarge":         true,
+	"i7i.large":           true,
+	"i7i.metal-24xl":      true,
+	"i7i.metal-48xl":      true,
+	"i7i.xlarge":          true,
+	"i7ie.12xlarge":       true,
+	"i7ie.18xlarge":       true,
+	"i7ie.24xlarge":       true,
+	"i7ie.2xlarge":        true,
+	"i7ie.3xlarge":        true,
+	"i7ie.48xlarge":       true,
+	"i7ie.6xlarge":        true,
+	"i7ie.large":          true,
+	"i7ie.metal-24xl":     true,
+	"i7ie.metal-48xl":     true,
+	"i7ie.xlarge":         true,
+	"i8g.12xlarge":        true,
+	"i8g.16xlarge":        true,
+	"i8g.24xlarge":        true,
+	"i8g.2xlarge":         true,
+	"i8g.48xlarge":        true,
+	"i8g.4xlarge":         true,
+	"i8g.8xlarge":         true,
+	"i8g.large":           true,
+	"i8g.metal-24xl":      true,
+	"i8g.metal-48xl":      true,
+	"i8g.xlarge":          true,
+	"i8ge.12xlarge":       true,
+	"i8ge.18xlarge":       true,
+	"i8ge.24xlarge":       true,
+	"i8ge.2xlarge":        true,
+	"i8ge.3xlarge":        true,
+	"i8ge.48xlarge":       true,
+	"i8ge.6xlarge":        true,
+	"i8ge.large":          true,
+	"i8ge.metal-24xl":     true,
+	"i8ge.metal-48xl":     true,
+	"i8ge.xlarge":         true,
+	"im4gn.16xlarge":      true,
+	"im4gn.2xlarge":       true,
+	"im4gn.4xlarge":       true,
+	"im4gn.8xlarge":       true,
+	"im4gn.large":         true,
+	"im4gn.xlarge":        true,
+	"inf1.24xlarge":       true,
+	"inf1.2xlarge":        true,
+	"inf1.6xlarge":        true,
+	"inf1.xlarge":         true,
+	"inf2.24xlarge":       true,
+	"inf2.48xlarge":       true,
+	"inf2.8xlarge":        true,
+	"inf2.xlarge":         true,
+	"is4gen.2xlarge":      true,
+	"is4gen.4xlarge":      true,
+	"is4gen.8xlarge":      true,
+	"is4gen.large":        true,
+	"is4gen.medium":       true,
+	"is4gen.xlarge":       true,
+	"m5.12xlarge":         true,
+	"m5.16xlarge":         true,
+	"m5.24xlarge":         true,
+	"m5.2xlarge":          true,
+	"m5.4xlarge":          true,
+	"m5.8xlarge":          true,
+	"m5.large":            true,
+	"m5.metal":            true,
+	"m5.xlarge":           true,
+	"m5a.12xlarge":        true,
+	"m5a.16xlarge":        true,
+	"m5a.24xlarge":        true,
+	"m5a.2xlarge":         true,
+	"m5a.4xlarge":         true,
+	"m5a.8xlarge":         true,
+	"m5a.large":           true,
+	"m5a.xlarge":          true,
+	"m5ad.12xlarge":       true,
+	"m5ad.16xlarge":       true,
+	"m5ad.24xlarge":       true,
+	"m5ad.2xlarge":        true,
+	"m5ad.4xlarge":        true,
+	"m5ad.8xlarge":        true,
+	"m5ad.large":          true,
+	"m5ad.xlarge":         true,
+	"m5d.12xlarge":        true,
+	"m5d.16xlarge":        true,
+	"m5d.24xlarge":        true,
+	"m5d.2xlarge":         true,
+	"m5d.4xlarge":         true,
+	"m5d.8xlarge":         true,
+	"m5d.large":           true,
+	"m5d.metal":           true,
+	"m5d.xlarge":          true,
+	"m5dn.12xlarge":       true,
+	"m5dn.16xlarge":       true,
+	"m5dn.24xlarge":       true,
+	"m5dn.2xlarge":        true,
+	"m5dn.4xlarge":        true,
+	"m5dn.8xlarge":        true,
+	"m5dn.large":          true,
+	"m5dn.metal":          true,
+	"m5dn.xlarge":         true,
+	"m5n.12xlarge":        true,
+	"m5n.16xlarge":        true,
+	"m5n.24xlarge":        true,
+	"m5n.2xlarge":         true,
+	"m5n.4xlarge":         true,
+	"m5n.8xlarge":         true,
+	"m5n.large":           true,
+	"m5n.metal":           true,
+	"m5n.xlarge":          true,
+	"m5zn.12xlarge":       true,
+	"m5zn.2xlarge":        true,
+	"m5zn.3xlarge":        true,
+	"m5zn.6xlarge":        true,
+	"m5zn.large":          true,
+	"m5zn.metal":          true,
+	"m5zn.xlarge":         true,
+	"m6a.12xlarge":        true,
+	"m6a.16xlarge":        true,
+	"m6a.24xlarge":        true,
+	"m6a.2xlarge":         true,
+	"m6a.32xlarge":        true,
+	"m6a.48xlarge":        true,
+	"m6a.4xlarge":         true,
+	"m6a.8xlarge":         true,
+	"m6a.large":           true,
+	"m6a.metal":           true,
+	"m6a.xlarge":          true,
+	"m6g.12xlarge":        true,
+	"m6g.16xlarge":        true,
+	"m6g.2xlarge":         true,
+	"m6g.4xlarge":         true,
+	"m6g.8xlarge":         true,
+	"m6g.large":           true,
+	"m6g.medium":          true,
+	"m6g.metal":           true,
+	"m6g.xlarge":          true,
+	"m6gd.12xlarge":       true,
+	"m6gd.16xlarge":       true,
+	"m6gd.2xlarge":        true,
+	"m6gd.4xlarge":        true,
+	"m6gd.8xlarge":        true,
+	"m6gd.large":          true,
+	"m6gd.medium":         true,
+	"m6gd.metal":          true,
+	"m6gd.xlarge":         true,
+	"m6i.12xlarge":        true,
+	"m6i.16xlarge":        true,
+	"m6i.24xlarge":        true,
+	"m6i.2xlarge":         true,
+	"m6i.32xlarge":        true,
+	"m6i.4xlarge":         true,
+	"m6i.8xlarge":         true,
+	"m6i.large":           true,
+	"m6i.metal":           true,
+	"m6i.xlarge":          true,
+	"m6id.12xlarge":       true,
+	"m6id.16xlarge":       true,
+	"m6id.24xlarge":       true,
+	"m6id.2xlarge":        true,
+	"m6id.32xlarge":       true,
+	"m6id.4xlarge":        true,
+	"m6id.8xlarge":        true,
+	"m6id.large":          true,
+	"m6id.metal":          true,
+	"m6id.xlarge":         true,
+	"m6idn.12xlarge":      true,
+	"m6idn.16xlarge":      true,
+	"m6idn.24xlarge":      true,
+	"m6idn.2xlarge":       true,
+	"m6idn.32xlarge":      true,
+	"m6idn.4xlarge":       true,
+	"m6idn.8xlarge":       true,
+	"m6idn.large":         true,
+	"m6idn.metal":         true,
+	"m6idn.xlarge":        true,
+	"m6in.12xlarge":       true,
+	"m6in.16xlarge":       true,
+	"m6in.24xlarge":       true,
+	"m6in.2xlarge":        true,
+	"m6in.32xlarge":       true,
+	"m6in.4xlarge":        true,
+	"m6in.8xlarge":        true,
+	"m6in.large":          true,
+	"m6in.metal":          true,
+	"m6in.xlarge":         true,
+	"m7a.12xlarge":        true,
+	"m7a.16xlarge":        true,
+	"m7a.24xlarge":        true,
+	"m7a.2xlarge":         true,
+	"m7a.32xlarge":        true,
+	"m7a.48xlarge":        true,
+	"m7a.4xlarge":         true,
+	"m7a.8xlarge":         true,
+	"m7a.large":           true,
+	"m7a.medium":          true,
+	"m7a.metal-48xl":      true,
+	"m7a.xlarge":          true,
+	"m7g-flex.2xlarge":    true,
+	"m7g-flex.4xlarge":    true,
+	"m7g-flex.8xlarge":    true,
+	"m7g-flex.large":      true,
+	"m7g-flex.medium":     true,
+	"m7g-flex.xlarge":     true,
+	"m7g.12xlarge":        true,
+	"m7g.16xlarge":        true,
+	"m7g.2xlarge":         true,
+	"m7g.4xlarge":         true,
+	"m7g.8xlarge":         true,
+	"m7g.large":           true,
+	"m7g.medium":          true,
+	"m7g.metal":           true,
+	"m7g.xlarge":          true,
+	"m7gd.12xlarge":       true,
+	"m7gd.16xlarge":       true,
+	"m7gd.2xlarge":        true,
+	"m7gd.4xlarge":        true,
+	"m7gd.8xlarge":        true,
+	"m7gd.large":          true,
+	"m7gd.medium":         true,
+	"m7gd.metal":          true,
+	"m7gd.xlarge":         true,
+	"m7i-flex.12xlarge":   true,
+	"m7i-flex.16xlarge":   true,
+	"m7i-flex.2xlarge":    true,
+	"m7i-flex.4xlarge":    true,
+	"m7i-flex.8xlarge":    true,
+	"m7i-flex.large":      true,
+	"m7i-flex.xlarge":     true,
+	"m7i.12xlarge":        true,
+	"m7i.16xlarge":        true,
+	"m7i.24xlarge":        true,
+	"m7i.2xlarge":         true,
+	"m7i.48xlarge":        true,
+	"m7i.4xlarge":         true,
+	"m7i.8xlarge":         true,
+	"m7i.large":           true,
+	"m7i.metal-24xl":      true,
+	"m7i.metal-48xl":      true,
+	"m7i.xlarge":          true,
+	"m8a.12xlarge":        true,
+	"m8a.16xlarge":        true,
+	"m8a.24xlarge":        true,
+	"m8a.2xlarge":         true,
+	"m8a.48xlarge":        true,
+	"m8a.4xlarge":         true,
+	"m8a.8xlarge":         true,
+	"m8a.large":           true,
+	"m8a.medium":          true,
+	"m8a.metal-24xl":      true,
+	"m8a.metal-48xl":      true,
+	"m8a.xlarge":          true,
+	"m8azn.12xlarge":      true,
+	"m8azn.24xlarge":      true,
+	"m8azn.3xlarge":       true,
+	"m8azn.6xlarge":       true,
+	"m8azn.large":         true,
+	"m8azn.medium":        true,
+	"m8azn.metal-12xl":    true,
+	"m8azn.metal-24xl":    true,
+	"m8azn.xlarge":        true,
+	"m8g-flex.12xlarge":   true,
+	"m8g-flex.16xlarge":   true,
+	"m8g-flex.2xlarge":    true,
+	"m8g-flex.4xlarge":    true,
+	"m8g-flex.8xlarge":    true,
+	"m8g-flex.large":      true,
+	"m8g-flex.medium":     true,
+	"m8g-flex.xlarge":     true,
+	"m8g.12xlarge":        true,
+	"m8g.16xlarge":        true,
+	"m8g.24xlarge":        true,
+	"m8g.2xlarge":         true,
+	"m8g.48xlarge":        true,
+	"m8g.4xlarge":         true,
+	"m8g.8xlarge":         true,
+	"m8g.large":           true,
+	"m8g.medium":          true,
+	"m8g.metal-24xl":      true,
+	"m8g.metal-48xl":      true,
+	"m8g.xlarge":          true,
+	"m8gb.12xlarge":       true,
+	"m8gb.16xlarge":       true,
+	"m8gb.24xlarge":       true,
+	"m8gb.2xlarge":        true,
+	"m8gb.48xlarge":       true,
+	"m8gb.4xlarge":        true,
+	"m8gb.8xlarge":        true,
+	"m8gb.large":          true,
+	"m8gb.medium":         true,
+	"m8gb.metal-24xl":     true,
+	"m8gb.metal-48xl":     true,
+	"m8gb.xlarge":         true,
+	"m8gd.12xlarge":       true,
+	"m8gd.16xlarge":       true,
+	"m8gd.24xlarge":       true,
+	"m8gd.2xlarge":        true,
+	"m8gd.48xlarge":       true,
+	"m8gd.4xlarge":        true,
+	"m8gd.8xlarge":        true,
+	"m8gd.large":          true,
+	"m8gd.medium":         true,
+	"m8gd.metal-24xl":     true,
+	"m8gd.metal-48xl":     true,
+	"m8gd.xlarge":         true,
+	"m8gn.12xlarge":       true,
+	"m8gn.16xlarge":       true,
+	"m8gn.24xlarge":       true,
+	"m8gn.2xlarge":        true,
+	"m8gn.48xlarge":       true,
+	"m8gn.4xlarge":        true,
+	"m8gn.8xlarge":        true,
+	"m8gn.large":          true,
+	"m8gn.medium":         true,
+	"m8gn.metal-24xl":     true,
+	"m8gn.metal-48xl":     true,
+	"m8gn.xlarge":         true,
+	"m8i-flex.12xlarge":   true,
+	"m8i-flex.16xlarge":   true,
+	"m8i-flex.2xlarge":    true,
+	"m8i-flex.4xlarge":    true,
+	"m8i-flex.8xlarge":    true,
+	"m8i-flex.large":      true,
+	"m8i-flex.xlarge":     true,
+	"m8i.12xlarge":        true,
+	"m8i.16xlarge":        true,
+	"m8i.24xlarge":        true,
+	"m8i.2xlarge":         true,
+	"m8i.32xlarge":        true,
+	"m8i.48xlarge":        true,
+	"m8i.4xlarge":         true,
+	"m8i.8xlarge":         true,
+	"m8i.96xlarge":        true,
+	"m8i.large":           true,
+	"m8i.metal-48xl":      true,
+	"m8i.metal-96xl":      true,
+	"m8i.xlarge":          true,
+	"m8ib.12xlarge":       true,
+	"m8ib.16xlarge":       true,
+	"m8ib.24xlarge":       true,
+	"m8ib.2xlarge":        true,
+	"m8ib.32xlarge":       true,
+	"m8ib.48xlarge":       true,
+	"m8ib.4xlarge":        true,
+	"m8ib.8xlarge":        true,
+	"m8ib.96xlarge":       true,
+	"m8ib.large":          true,
+	"m8ib.metal-48xl":     true,
+	"m8ib.metal-96xl":     true,
+	"m8ib.xlarge":         true,
+	"m8id.12xlarge":       true,
+	"m8id.16xlarge":       true,
+	"m8id.24xlarge":       true,
+	"m8id.2xlarge":        true,
+	"m8id.32xlarge":       true,
+	"m8id.48xlarge":       true,
+	"m8id.4xlarge":        true,
+	"m8id.8xlarge":        true,
+	"m8id.96xlarge":       true,
+	"m8id.large":          true,
+	"m8id.metal-48xl":     true,
+	"m8id.metal-96xl":     true,
+	"m8id.xlarge":         true,
+	"m8idb.12xlarge":      true,
+	"m8idb.16xlarge":      true,
+	"m8idb.24xlarge":      true,
+	"m8idb.2xlarge":       true,
+	"m8idb.32xlarge":      true,
+	"m8idb.48xlarge":      true,
+	"m8idb.4xlarge":       true,
+	"m8idb.8xlarge":       true,
+	"m8idb.96xlarge":      true,
+	"m8idb.large":         true,
+	"m8idb.metal-48xl":    true,
+	"m8idb.metal-96xl":    true,
+	"m8idb.xlarge":        true,
+	"m8idn.12xlarge":      true,
+	"m8idn.16xlarge":      true,
+	"m8idn.24xlarge":      true,
+	"m8idn.2xlarge":       true,
+	"m8idn.32xlarge":      true,
+	"m8idn.48xlarge":      true,
+	"m8idn.4xlarge":       true,
+	"m8idn.8xlarge":       true,
+	"m8idn.96xlarge":      true,
+	"m8idn.large":         true,
+	"m8idn.metal-48xl":    true,
+	"m8idn.metal-96xl":    true,
+	"m8idn.xlarge":        true,
+	"m8in.12xlarge":       true,
+	"m8in.16xlarge":       true,
+	"m8in.24xlarge":       true,
+	"m8in.2xlarge":        true,
+	"m8in.32xlarge":       true,
+	"m8in.48xlarge":       true,
+	"m8in.4xlarge":        true,
+	"m8in.8xlarge":        true,
+	"m8in.96xlarge":       true,
+	"m8in.large":          true,
+	"m8in.metal-48xl":     true,
+	"m8in.metal-96xl":     true,
+	"m8in.xlarge":         true,
+	"m8ine.12xlarge":      true,
+	"m8ine.2xlarge":       true,
+	"m8ine.4xlarge":       true,
+	"m8ine.8xlarge":       true,
+	"m8ine.large":         true,
+	"m8ine.xlarge":        true,
+	"m9g.12xlarge":        true,
+	"m9g.16xlarge":        true,
+	"m9g.24xlarge":        true,
+	"m9g.2xlarge":         true,
+	"m9g.48xlarge":        true,
+	"m9g.4xlarge":         true,
+	"m9g.8xlarge":         true,
+	"m9g.large":           true,
+	"m9g.medium":          true,
+	"m9g.metal-48xl":      true,
+	"m9g.xlarge":          true,
+	"m9gd.12xlarge":       true,
+	"m9gd.16xlarge":       true,
+	"m9gd.24xlarge":       true,
+	"m9gd.2xlarge":        true,
+	"m9gd.48xlarge":       true,
+	"m9gd.4xlarge":        true,
+	"m9gd.8xlarge":        true,
+	"m9gd.large":          true,
+	"m9gd.medium":         true,
+	"m9gd.metal-48xl":     true,
+	"m9gd.xlarge":         true,
+	"mac-m3ultra.metal":   true,
+	"mac-m4.metal":        true,
+	"mac-m4max.metal":     true,
+	"mac-m4pro.metal":     true,
+	"mac1.metal":          true,
+	"mac2-m1ultra.metal":  true,
+	"mac2-m2.metal":       true,
+	"mac2-m2pro.metal":    true,
+	"mac2.metal":          true,
+	"p3dn.24xlarge":       true,
+	"p4d.24xlarge":        true,
+	"p4de.24xlarge":       true,
+	"p5.48xlarge":         true,
+	"p5.4xlarge":          true,
+	"p5e.48xlarge":        true,
+	"p5en.48xlarge":       true,
+	"p6-b200.48xlarge":    true,
+	"p6-b300.48xlarge":    true,
+	"r5.12xlarge":         true,
+	"r5.16xlarge":         true,
+	"r5.24xlarge":         true,
+	"r5.2xlarge":          true,
+	"r5.4xlarge":          true,
+	"r5.8xlarge":          true,
+	"r5.large":            true,
+	"r5.metal":            true,
+	"r5.xlarge":           true,
+	"r5a.12xlarge":        true,
+	"r5a.16xlarge":        true,
+	"r5a.24xlarge":        true,
+	"r5a.2xlarge":         true,
+	"r5a.4xlarge":         true,
+	"r5a.8xlarge":         true,
+	"r5a.large":           true,
+	"r5a.xlarge":          true,
+	"r5ad.12xlarge":       true,
+	"r5ad.16xlarge":       true,
+	"r5ad.24xlarge":       true,
+	"r5ad.2xlarge":        true,
+	"r5ad.4xlarge":        true,
+	"r5ad.8xlarge":        true,
+	"r5ad.large":          true,
+	"r5ad.xlarge":         true,
+	"r5b.12xlarge":        true,
+	"r5b.16xlarge":        true,
+	"r5b.24xlarge":        true,
+	"r5b.2xlarge":         true,
+	"r5b.4xlarge":         true,
+	"r5b.8xlarge":         true,
+	"r5b.large":           true,
+	"r5b.metal":           true,
+	"r5b.xlarge":          true,
+	"r5d.12xlarge":        true,
+	"r5d.16xlarge":        true,
+	"r5d.24xlarge":        true,
+	"r5d.2xlarge":         true,
+	"r5d.4xlarge":         true,
+	"r5d.8xlarge":         true,
+	"r5d.large":           true,
+	"r5d.metal":           true,
+	"r5d.xlarge":          true,
+	"r5dn.12xlarge":       true,
+	"r5dn.16xlarge":       true,
+	"r5dn.24xlarge":       true,
+	"r5dn.2xlarge":        true,
+	"r5dn.4xlarge":        true,
+	"r5dn.8xlarge":        true,
+	"r5dn.large":          true,
+	"r5dn.metal":          true,
+	"r5dn.xlarge":         true,
+	"r5n.12xlarge":        true,
+	"r5n.16xlarge":        true,
+	"r5n.24xlarge":        true,
+	"r5n.2xlarge":         true,
+	"r5n.4xlarge":         true,
+	"r5n.8xlarge":         true,
+	"r5n.large":           true,
+	"r5n.metal":           true,
+	"r5n.xlarge":          true,
+	"r6a.12xlarge":        true,
+	"r6a.16xlarge":        true,
+	"r6a.24xlarge":        true,
+	"r6a.2xlarge":         true,
+	"r6a.32xlarge":        true,
+	"r6a.48xlarge":        true,
+	"r6a.4xlarge":         true,
+	"r6a.8xlarge":         true,
+	"r6a.large":           true,
+	"r6a.metal":           true,
+	"r6a.xlarge":          true,
+	"r6g.12xlarge":        true,
+	"r6g.16xlarge":        true,
+	"r6g.2xlarge":         true,
+	"r6g.4xlarge":         true,
+	"r6g.8xlarge":         true,
+	"r6g.large":           true,
+	"r6g.medium":          true,
+	"r6g.metal":           true,
+	"r6g.xlarge":          true,
+	"r6gd.12xlarge":       true,
+	"r6gd.16xlarge":       true,
+	"r6gd.2xlarge":        true,
+	"r6gd.4xlarge":        true,
+	"r6gd.8xlarge":        true,
+	"r6gd.large":          true,
+	"r6gd.medium":         true,
+	"r6gd.metal":          true,
+	"r6gd.xlarge":         true,
+	"r6i.12xlarge":        true,
+	"r6i.16xlarge":        true,
+	"r6i.24xlarge":        true,
+	"r6i.2xlarge":         true,
+	"r6i.32xlarge":        true,
+	"r6i.4xlarge":         true,
+	"r6i.8xlarge":         true,
+	"r6i.large":           true,
+	"r6i.metal":           true,
+	"r6i.xlarge":          true,
+	"r6id.12xlarge":       true,
+	"r6id.16xlarge":       true,
+	"r6id.24xlarge":       true,
+	"r6id.2xlarge":        true,
+	"r6id.32xlarge":       true,
+	"r6id.4xlarge":        true,
+	"r6id.8xlarge":        true,
+	"r6id.large":          true,
+	"r6id.metal":          true,
+	"r6id.xlarge":         true,
+	"r6idn.12xlarge":      true,
+	"r6idn.16xlarge":      true,
+	"r6idn.24xlarge":      true,
+	"r6idn.2xlarge":       true,
+	"r6idn.32xlarge":      true,
+	"r6idn.4xlarge":       true,
+	"r6idn.8xlarge":       true,
+	"r6idn.large":         true,
+	"r6idn.metal":         true,
+	"r6idn.xlarge":        true,
+	"r6in.12xlarge":       true,
+	"r6in.16xlarge":       true,
+	"r6in.24xlarge":       true,
+	"r6in.2xlarge":        true,
+	"r6in.32xlarge":       true,
+	"r6in.4xlarge":        true,
+	"r6in.8xlarge":        true,
+	"r6in.large":          true,
+	"r6in.metal":          true,
+	"r6in.xlarge":         true,
+	"r7a.12xlarge":        true,
+	"r7a.16xlarge":        true,
+	"r7a.24xlarge":        true,
+	"r7a.2xlarge":         true,
+	"r7a.32xlarge":        true,
+	"r7a.48xlarge":        true,
+	"r7a.4xlarge":         true,
+	"r7a.8xlarge":         true,
+	"r7a.large":           true,
+	"r7a.medium":          true,
+	"r7a.metal-48xl":      true,
+	"r7a.xlarge":          true,
+	"r7g.12xlarge":        true,
+	"r7g.16xlarge":        true,
+	"r7g.2xlarge":         true,
+	"r7g.4xlarge":         true,
+	"r7g.8xlarge":         true,
+	"r7g.large":           true,
+	"r7g.medium":          true,
+	"r7g.metal":           true,
+	"r7g.xlarge":          true,
+	"r7gd.12xlarge":       true,
+	"r7gd.16xlarge":       true,
+	"r7gd.2xlarge":        true,
+	"r7gd.4xlarge":        true,
+	"r7gd.8xlarge":        true,
+	"r7gd.large":          true,
+	"r7gd.medium":         true,
+	"r7gd.metal":          true,
+	"r7gd.xlarge":         true,
+	"r7i.12xlarge":        true,
+	"r7i.16xlarge":        true,
+	"r7i.24xlarge":        true,
+	"r7i.2xlarge":         true,
+	"r7i.48xlarge":        true,
+	"r7i.4xlarge":         true,
+	"r7i.8xlarge":         true,
+	"r7i.large":           true,
+	"r7i.metal-24xl":      true,
+	"r7i.metal-48xl":      true,
+	"r7i.xlarge":          true,
+	"r7iz.12xlarge":       true,
+	"r7iz.16xlarge":       true,
+	"r7iz.2xlarge":        true,
+	"r7iz.32xlarge":       true,
+	"r7iz.4xlarge":        true,
+	"r7iz.8xlarge":        true,
+	"r7iz.large":          true,
+	"r7iz.metal-16xl":     true,
+	"r7iz.metal-32xl":     true,
+	"r7iz.xlarge":         true,
+	"r8a.12xlarge":        true,
+	"r8a.16xlarge":        true,
+	"r8a.24xlarge":        true,
+	"r8a.2xlarge":         true,
+	"r8a.48xlarge":        true,
+	"r8a.4xlarge":         true,
+	"r8a.8xlarge":         true,
+	"r8a.large":           true,
+	"r8a.medium":          true,
+	"r8a.metal-24xl":      true,
+	"r8a.metal-48xl":      true,
+	"r8a.xlarge":          true,
+	"r8g.12xlarge":        true,
+	"r8g.16xlarge":        true,
+	"r8g.24xlarge":        true,
+	"r8g.2xlarge":         true,
+	"r8g.48xlarge":        true,
+	"r8g.4xlarge":         true,
+	"r8g.8xlarge":         true,
+	"r8g.large":           true,
+	"r8g.medium":          true,
+	"r8g.metal-24xl":      true,
+	"r8g.metal-48xl":      true,
+	"r8g.xlarge":          true,
+	"r8gb.12xlarge":       true,
+	"r8gb.16xlarge":       true,
+	"r8gb.24xlarge":       true,
+	"r8gb.2xlarge":        true,
+	"r8gb.48xlarge":       true,
+	"r8gb.4xlarge":        true,
+	"r8gb.8xlarge":        true,
+	"r8gb.large":          true,
+	"r8gb.medium":         true,
+	"r8gb.metal-24xl":     true,
+	"r8gb.metal-48xl":     true,
+	"r8gb.xlarge":         true,
+	"r8gd.12xlarge":       true,
+	"r8gd.16xlarge":       true,
+	"r8gd.24xlarge":       true,
+	"r8gd.2xlarge":        true,
+	"r8gd.48xlarge":       true,
+	"r8gd.4xlarge":        true,
+	"r8gd.8xlarge":        true,
+	"r8gd.large":          true,
+	"r8gd.medium":         true,
+	"r8gd.metal-24xl":     true,
+	"r8gd.metal-48xl":     true,
+	"r8gd.xlarge":         true,
+	"r8gn.12xlarge":       true,
+	"r8gn.16xlarge":       true,
+	"r8gn.24xlarge":       true,
+	"r8gn.2xlarge":        true,
+	"r8gn.48xlarge":       true,
+	"r8gn.4xlarge":        true,
+	"r8gn.8xlarge":        true,
+	"r8gn.large":          true,
+	"r8gn.medium":         true,
+	"r8gn.metal-24xl":     true,
+	"r8gn.metal-48xl":     true,
+	"r8gn.xlarge":         true,
+	"r8i-flex.12xlarge":   true,
+	"r8i-flex.16xlarge":   true,
+	"r8i-flex.2xlarge":    true,
+	"r8i-flex.4xlarge":    true,
+	"r8i-flex.8xlarge":    true,
+	"r8i-flex.large":      true,
+	"r8i-flex.xlarge":     true,
+	"r8i.12xlarge":        true,
+	"r8i.16xlarge":        true,
+	"r8i.24xlarge":        true,
+	"r8i.2xlarge":         true,
+	"r8i.32xlarge":        true,
+	"r8i.48xlarge":        true,
+	"r8i.4xlarge":         true,
+	"r8i.8xlarge":         true,
+	"r8i.96xlarge":        true,
+	"r8i.large":           true,
+	"r8i.metal-48xl":      true,
+	"r8i.metal-96xl":      true,
+	"r8i.xlarge":          true,
+	"r8ib.12xlarge":       true,
+	"r8ib.16xlarge":       true,
+	"r8ib.24xlarge":       true,
+	"r8ib.2xlarge":        true,
+	"r8ib.32xlarge":       true,
+	"r8ib.48xlarge":       true,
+	"r8ib.4xlarge":        true,
+	"r8ib.8xlarge":        true,
+	"r8ib.96xlarge":       true,
+	"r8ib.large":          true,
+	"r8ib.metal-48xl":     true,
+	"r8ib.metal-96xl":     true,
+	"r8ib.xlarge":         true,
+	"r8id.12xlarge":       true,
+	"r8id.16xlarge":       true,
+	"r8id.24xlarge":       true,
+	"r8id.2xlarge":        true,
+	"r8id.32xlarge":       true,
+	"r8id.48xlarge":       true,
+	"r8id.4xlarge":        true,
+	"r8id.8xlarge":        true,
+	"r8id.96xlarge":       true,
+	"r8id.large":          true,
+	"r8id.metal-48xl":     true,
+	"r8id.metal-96xl":     true,
+	"r8id.xlarge":         true,
+	"r8idb.12xlarge":      true,
+	"r8idb.16xlarge":      true,
+	"r8idb.24xlarge":      true,
+	"r8idb.2xlarge":       true,
+	"r8idb.32xlarge":      true,
+	"r8idb.48xlarge":      true,
+	"r8idb.4xlarge":       true,
+	"r8idb.8xlarge":       true,
+	"r8idb.96xlarge":      true,
+	"r8idb.large":         true,
+	"r8idb.metal-48xl":    true,
+	"r8idb.metal-96xl":    true,
+	"r8idb.xlarge":        true,
+	"r8idn.12xlarge":      true,
+	"r8idn.16xlarge":      true,
+	"r8idn.24xlarge":      true,
+	"r8idn.2xlarge":       true,
+	"r8idn.32xlarge":      true,
+	"r8idn.48xlarge":      true,
+	"r8idn.4xlarge":       true,
+	"r8idn.8xlarge":       true,
+	"r8idn.96xlarge":      true,
+	"r8idn.large":         true,
+	"r8idn.metal-48xl":    true,
+	"r8idn.metal-96xl":    true,
+	"r8idn.xlarge":        true,
+	"r8in.12xlarge":       true,
+	"r8in.16xlarge":       true,
+	"r8in.24xlarge":       true,
+	"r8in.2xlarge":        true,
+	"r8in.32xlarge":       true,
+	"r8in.48xlarge":       true,
+	"r8in.4xlarge":        true,
+	"r8in.8xlarge":        true,
+	"r8in.96xlarge":       true,
+	"r8in.large":          true,
+	"r8in.metal-48xl":     true,
+	"r8in.metal-96xl":     true,
+	"r8in.xlarge":         true,
+	"r9g.12xlarge":        true,
+	"r9g.16xlarge":        true,
+	"r9g.24xlarge":        true,
+	"r9g.2xlarge":         true,
+	"r9g.48xlarge":        true,
+	"r9g.4xlarge":         true,
+	"r9g.8xlarge":         true,
+	"r9g.large":           true,
+	"r9g.medium":          true,
+	"r9g.metal-48xl":      true,
+	"r9g.xlarge":          true,
+	"r9gd.12xlarge":       true,
+	"r9gd.16xlarge":       true,
+	"r9gd.24xlarge":       true,
+	"r9gd.2xlarge":        true,
+	"r9gd.48xlarge":       true,
+	"r9gd.4xlarge":        true,
+	"r9gd.8xlarge":        true,
+	"r9gd.large":          true,
+	"r9gd.medium":         true,
+	"r9gd.metal-48xl":     true,
+	"r9gd.xlarge":         true,
+	"trn1.2xlarge":        true,
+	"trn1.32xlarge":       true,
+	"trn1n.32xlarge":      true,
+	"u-3tb1.56xlarge":     true,
+	"u7i-12tb.224xlarge":  true,
+	"u7i-6tb.112xlarge":   true,
+	"u7i-8tb.112xlarge":   true,
+	"u7in-16tb.224xlarge": true,
+	"u7in-24tb.224xlarge": true,
+	"u7in-32tb.224xlarge": true,
+	"vt1.24xlarge":        true,
+	"vt1.3xlarge":         true,
+	"vt1.6xlarge":         true,
+	"x2gd.12xlarge":       true,
+	"x2gd.16xlarge":       true,
+	"x2gd.2xlarge":        true,
+	"x2gd.4xlarge":        true,
+	"x2gd.8xlarge":        true,
+	"x2gd.large":          true,
+	"x2gd.medium":         true,
+	"x2gd.metal":          true,
+	"x2gd.xlarge":         true,
+	"x2idn.16xlarge":      true,
+	"x2idn.24xlarge":      true,
+	"x2idn.32xlarge":      true,
+	"x2idn.metal":         true,
+	"x2iedn.16xlarge":     true,
+	"x2iedn.24xlarge":     true,
+	"x2iedn.2xlarge":      true,
+	"x2iedn.32xlarge":     true,
+	"x2iedn.4xlarge":      true,
+	"x2iedn.8xlarge":      true,
+	"x2iedn.metal":        true,
+	"x2iedn.xlarge":       true,
+	"x2iezn.12xlarge":     true,
+	"x2iezn.2xlarge":      true,
+	"x2iezn.4xlarge":      true,
+	"x2iezn.6xlarge":      true,
+	"x2iezn.8xlarge":      true,
+	"x2iezn.metal":        true,
+	"x8aedz.12xlarge":     true,
+	"x8aedz.24xlarge":     true,
+	"x8aedz.3xlarge":      true,
+	"x8aedz.6xlarge":      true,
+	"x8aedz.large":        true,
+	"x8aedz.metal-12xl":   true,
+	"x8aedz.metal-24xl":   true,
+	"x8aedz.xlarge":       true,
+	"x8g.12xlarge":        true,
+	"x8g.16xlarge":        true,
+	"x8g.24xlarge":        true,
+	"x8g.2xlarge":         true,
+	"x8g.48xlarge":        true,
+	"x8g.4xlarge":         true,
+	"x8g.8xlarge":         true,
+	"x8g.large":           true,
+	"x8g.medium":          true,
+	"x8g.metal-24xl":      true,
+	"x8g.metal-48xl":      true,
+	"x8g.xlarge":          true,
+	"x8i.12xlarge":        true,
+	"x8i.16xlarge":        true,
+	"x8i.24xlarge":        true,
+	"x8i.2xlarge":         true,
+	"x8i.32xlarge":        true,
+	"x8i.48xlarge":        true,
+	"x8i.4xlarge":         true,
+	"x8i.64xlarge":        true,
+	"x8i.8xlarge":         true,
+	"x8i.96xlarge":        true,
+	"x8i.large":           true,
+	"x8i.metal-48xl":      true,
+	"x8i.metal-96xl":      true,
+	"x8i.xlarge":          true,
+	"z1d.12xlarge":        true,
+	"z1d.2xlarge":         true,
+	"z1d.3xlarge":         true,
+	"z1d.6xlarge":         true,
+	"z1d.large":           true,
+	"z1d.metal":           true,
+	"z1d.xlarge":          true,
+}
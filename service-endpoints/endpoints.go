@@ -3,69 +3,195 @@ package network
 import (
 	"fmt"
 
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws"
 	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 )
 
 type VpcEndpoints struct {
 	pulumi.ResourceState
+	// keyed by service, eg. "ecr.api"
+	Endpoints map[string]*ec2.VpcEndpoint
 }
 
 type VpcEndpointsArgs struct {
-	GatewayEndpointServices   pulumi.StringArrayInput
-	InterfaceEndpointServices pulumi.StringArrayInput
-	SubnetIds                 pulumi.StringArrayInput
-	SecurityGroupIds          pulumi.StringArrayInput
-	RouteTableIds             pulumi.StringArrayInput
-	VpcId                     pulumi.StringInput
+	// Plain service names ("ecr.api", "s3"...) so resource names are stable
+	GatewayEndpointServices   []string
+	InterfaceEndpointServices []string
+	// Adds a known service set to the lists above, eg. PresetEksPrivate
+	Preset string
+	// Components running in the cluster (ComponentKarpenter...), preview warns about their services without endpoint
+	Components       []string
+	SubnetIds        pulumi.StringArrayInput
+	SecurityGroupIds pulumi.StringArrayInput
+	RouteTableIds    pulumi.StringArrayInput
+	VpcId            pulumi.StringInput
+	// Defaults to the provider region
+	Region string
+	// Creates a security group allowing 443 from the VPC CIDRs (and IPv6), added to SecurityGroupIds
+	CreateSecurityGroup bool
+	// IPv6 associations of the VPC past the first one, for the created security group
+	Ipv6CidrBlocks pulumi.StringArrayInput
+	// Endpoint policies: interface endpoints only for our account (or OrganizationId),
+	// the s3 gateway only for ECR layer buckets and S3BucketArns
+	RestrictAccess bool
+	OrganizationId string
+	S3BucketArns   []string
+	// Per service policy, wins over RestrictAccess. An empty string leaves the service without policy
+	EndpointPolicies map[string]pulumi.StringInput
+}
+
+// ServiceName builds the endpoint service name, eg. com.amazonaws.us-east-1.ecr.api or cn.com.amazonaws.cn-north-1.ecr.api
+func ServiceName(reverseDnsPrefix, region, service string) string {
+	return fmt.Sprintf("%s.%s.%s", reverseDnsPrefix, region, service)
+}
+
+// resolveServiceName asks the provider for the name so the partition prefix is right
+func resolveServiceName(ctx *pulumi.Context, region, service string) (string, error) {
+	result, err := aws.GetService(ctx, &aws.GetServiceArgs{
+		Region:    &region,
+		ServiceId: &service,
+	})
+	if err != nil {
+		return "", err
+	}
+	if result.ReverseDnsName != "" {
+		return result.ReverseDnsName, nil
+	}
+	return ServiceName(result.ReverseDnsPrefix, region, service), nil
 }
 
 func NewVpcEndpoints(ctx *pulumi.Context, name string, args *VpcEndpointsArgs, opts ...pulumi.ResourceOption) (*VpcEndpoints, error) {
-	componentResource := &VpcEndpoints{}
+	componentResource := &VpcEndpoints{Endpoints: map[string]*ec2.VpcEndpoint{}}
 
 	if args == nil {
 		args = &VpcEndpointsArgs{}
 	}
 
+	gatewayServices, interfaceServices, err := expandPreset(args)
+	if err != nil {
+		return nil, fmt.Errorf("vpc endpoints %q: %w", name, err)
+	}
+
+	uncovered, err := uncoveredServices(args.Components, append(append([]string{}, gatewayServices...), interfaceServices...))
+	if err != nil {
+		return nil, fmt.Errorf("vpc endpoints %q: %w", name, err)
+	}
+
+	region := args.Region
+	if region == "" {
+		current, err := aws.GetRegion(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		region = current.Name
+	}
+
+	partition, err := aws.GetPartition(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	account := config.New(ctx, "").GetSecret("account")
+
 	// <package>:<module>:<type>
-	err := ctx.RegisterComponentResource("my-own-cluster:network:VpcEndpoints", name, componentResource, opts...)
+	err = ctx.RegisterComponentResource("my-own-cluster:network:VpcEndpoints", name, componentResource, opts...)
 	if err != nil {
 		return nil, err
 	}
-	var interfaceEndpointServices []pulumi.StringInput = []pulumi.StringInput(args.InterfaceEndpointServices.(pulumi.StringArray))
 
-	for _, service := range interfaceEndpointServices {
+	warnUncovered(ctx, name, uncovered, componentResource)
 
-		_, err = ec2.NewVpcEndpoint(ctx, fmt.Sprintf("vpc-endpoint-%s", service), &ec2.VpcEndpointArgs{
+	securityGroupIds := pulumi.StringArray{}.ToStringArrayOutput()
+	if args.SecurityGroupIds != nil {
+		securityGroupIds = args.SecurityGroupIds.ToStringArrayOutput()
+	}
+
+	if args.CreateSecurityGroup {
+		endpointSecurityGroup, err := newEndpointSecurityGroup(ctx, name, args.VpcId, args.Ipv6CidrBlocks, componentResource)
+		if err != nil {
+			return nil, err
+		}
+
+		securityGroupIds = pulumi.All(endpointSecurityGroup.ID(), securityGroupIds).ApplyT(func(all []interface{}) []string {
+			return append([]string{string(all[0].(pulumi.ID))}, all[1].([]string)...)
+		}).(pulumi.StringArrayOutput)
+	}
+
+	policyFor := func(service string, restricted pulumi.StringOutput) pulumi.StringPtrInput {
+		if policy, ok := args.EndpointPolicies[service]; ok {
+			if policy == nil {
+				return nil
+			}
+			return policy.ToStringOutput().ApplyT(func(p string) *string {
+				if p == "" {
+					return nil
+				}
+				return &p
+			}).(pulumi.StringPtrOutput)
+		}
+		if args.RestrictAccess {
+			return restricted.ToStringPtrOutput()
+		}
+		return nil
+	}
+
+	for _, service := range interfaceServices {
+		serviceName, err := resolveServiceName(ctx, region, service)
+		if err != nil {
+			return nil, err
+		}
+
+		restricted := interfaceEndpointPolicy(account, args.OrganizationId)
+		if service == "sts" {
+			restricted = stsEndpointPolicy(account, args.OrganizationId)
+		}
+
+		endpoint, err := ec2.NewVpcEndpoint(ctx, fmt.Sprintf("%s-vpc-endpoint-%s", name, service), &ec2.VpcEndpointArgs{
 			VpcId:             args.VpcId,
 			AutoAccept:        pulumi.BoolPtr(true),
 			VpcEndpointType:   pulumi.StringPtr("Interface"),
-			ServiceName:       pulumi.Sprintf("com.amazonaws.us-east-1.%s", service),
+			ServiceName:       pulumi.String(serviceName),
 			SubnetIds:         args.SubnetIds,
 			PrivateDnsEnabled: pulumi.BoolPtr(true),
-			SecurityGroupIds:  args.SecurityGroupIds,
+			SecurityGroupIds:  securityGroupIds,
+			Policy:            policyFor(service, restricted),
 		}, pulumi.Parent(componentResource))
 
 		if err != nil {
 			return nil, err
 		}
+
+		componentResource.Endpoints[service] = endpoint
 	}
 
-	var gatewayEndpointServices []pulumi.StringInput = []pulumi.StringInput(args.GatewayEndpointServices.(pulumi.StringArray))
+	for _, service := range gatewayServices {
+		serviceName, err := resolveServiceName(ctx, region, service)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, service := range gatewayEndpointServices {
+		// dynamodb is the other gateway service
+		restricted := interfaceEndpointPolicy(account, args.OrganizationId)
+		if service == "s3" {
+			restricted = s3GatewayPolicy(partition.Partition, region, account, args.OrganizationId, args.S3BucketArns)
+		}
 
-		_, err = ec2.NewVpcEndpoint(ctx, fmt.Sprintf("vpc-endpoint-%s", service), &ec2.VpcEndpointArgs{
+		endpoint, err := ec2.NewVpcEndpoint(ctx, fmt.Sprintf("%s-vpc-endpoint-%s", name, service), &ec2.VpcEndpointArgs{
 			VpcId:           args.VpcId,
 			AutoAccept:      pulumi.BoolPtr(true),
 			VpcEndpointType: pulumi.StringPtr("Gateway"),
-			ServiceName:     pulumi.Sprintf("com.amazonaws.us-east-1.%s", service),
+			ServiceName:     pulumi.String(serviceName),
 			RouteTableIds:   args.RouteTableIds,
+			Policy:          policyFor(service, restricted),
 		}, pulumi.Parent(componentResource))
 
 		if err != nil {
 			return nil, err
 		}
+
+		componentResource.Endpoints[service] = endpoint
 	}
 
 	ctx.RegisterResourceOutputs(componentResource, pulumi.Map{})
@@ -0,0 +1,84 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func TestServiceName(t *testing.T) {
+	tests := []struct {
+		prefix, region, service string
+		want                    string
+	}{
+		{"com.amazonaws", "us-east-1", "ecr.api", "com.amazonaws.us-east-1.ecr.api"},
+		{"cn.com.amazonaws", "cn-north-1", "ecr.dkr", "cn.com.amazonaws.cn-north-1.ecr.dkr"},
+		{"com.amazonaws", "us-gov-west-1", "sts", "com.amazonaws.us-gov-west-1.sts"},
+	}
+	for _, test := range tests {
+		if got := ServiceName(test.prefix, test.region, test.service); got != test.want {
+			t.Errorf("ServiceName(%q, %q, %q) = %q, want %q", test.prefix, test.region, test.service, got, test.want)
+		}
+	}
+}
+
+// getServiceResult is what the provider answers for a region, reverseDnsName is empty when it only knows the prefix
+type getServiceResult struct {
+	reverseDnsPrefix string
+	reverseDnsName   string
+}
+
+// getServiceMocks answers aws.GetService with fixed results by region
+type getServiceMocks map[string]getServiceResult
+
+func (getServiceMocks) NewResource(args pulumi.MockResourceArgs) (string, resource.PropertyMap, error) {
+	return args.Name + "_id", args.Inputs, nil
+}
+
+func (m getServiceMocks) Call(args pulumi.MockCallArgs) (resource.PropertyMap, error) {
+	if args.Token != "aws:index/getService:getService" {
+		return resource.PropertyMap{}, nil
+	}
+	result := m[args.Args["region"].StringValue()]
+	return resource.NewPropertyMapFromMap(map[string]interface{}{
+		"region":           args.Args["region"].StringValue(),
+		"serviceId":        args.Args["serviceId"].StringValue(),
+		"reverseDnsPrefix": result.reverseDnsPrefix,
+		"reverseDnsName":   result.reverseDnsName,
+	}), nil
+}
+
+func TestResolveServiceName(t *testing.T) {
+	mocks := getServiceMocks{
+		// aws and aws-cn only return the prefix, the name is built from it
+		"eu-west-1":      {reverseDnsPrefix: "com.amazonaws"},
+		"cn-northwest-1": {reverseDnsPrefix: "cn.com.amazonaws"},
+		// the full name wins over a prefix that would build another one
+		"us-gov-east-1": {reverseDnsPrefix: "gov.com.amazonaws", reverseDnsName: "com.amazonaws.us-gov-east-1.ec2-fips"},
+	}
+
+	tests := []struct {
+		partition, region, service string
+		want                       string
+	}{
+		{"aws", "eu-west-1", "ecr.api", "com.amazonaws.eu-west-1.ecr.api"},
+		{"aws-cn", "cn-northwest-1", "sts", "cn.com.amazonaws.cn-northwest-1.sts"},
+		{"aws-us-gov", "us-gov-east-1", "ec2", "com.amazonaws.us-gov-east-1.ec2-fips"},
+	}
+	for _, test := range tests {
+		var got string
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			var err error
+			got, err = resolveServiceName(ctx, test.region, test.service)
+			return err
+		}, pulumi.WithMocks("project", "stack", mocks))
+
+		if err != nil {
+			t.Fatalf("%s: resolveServiceName(%q, %q): %v", test.partition, test.region, test.service, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: resolveServiceName(%q, %q) = %q, want %q", test.partition, test.region, test.service, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-aws/sdk/v6/go/aws/ec2"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// principalCondition limits callers to our account, or to the whole organization when orgId is set
+func principalCondition(account pulumi.StringInput, orgId string) pulumi.StringOutput {
+	if orgId != "" {
+		return pulumi.Sprintf(`"StringEquals": {
+          "aws:PrincipalOrgID": "%s"
+        }`, orgId)
+	}
+	return pulumi.Sprintf(`"StringEquals": {
+          "aws:PrincipalAccount": "%s"
+        }`, account)
+}
+
+// interfaceEndpointPolicy allows any action on the service for our own principals only
+func interfaceEndpointPolicy(account pulumi.StringInput, orgId string) pulumi.StringOutput {
+	return pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "AllowOwnPrincipals",
+      "Effect": "Allow",
+      "Principal": "*",
+      "Action": "*",
+      "Resource": "*",
+      "Condition": {
+        %s
+      }
+    }
+  ]
+}`, principalCondition(account, orgId))
+}
+
+// stsEndpointPolicy is interfaceEndpointPolicy plus AssumeRoleWithWebIdentity for anyone, IRSA pods call it
+// unsigned with the service account token so there is no principal to match
+func stsEndpointPolicy(account pulumi.StringInput, orgId string) pulumi.StringOutput {
+	return pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "AllowOwnPrincipals",
+      "Effect": "Allow",
+      "Principal": "*",
+      "Action": "*",
+      "Resource": "*",
+      "Condition": {
+        %s
+      }
+    },
+    {
+      "Sid": "AllowWebIdentity",
+      "Effect": "Allow",
+      "Principal": "*",
+      "Action": "sts:AssumeRoleWithWebIdentity",
+      "Resource": "*"
+    }
+  ]
+}`, principalCondition(account, orgId))
+}
+
+// s3GatewayPolicy allows pulling image layers from the ECR buckets (owned by AWS, so no principal condition)
+// and full access to our own buckets
+// https://docs.aws.amazon.com/AmazonECR/latest/userguide/vpc-endpoints.html#ecr-setting-up-s3-gateway
+func s3GatewayPolicy(partition, region string, account pulumi.StringInput, orgId string, bucketArns []string) pulumi.StringOutput {
+	ownBuckets := ""
+	for _, bucketArn := range bucketArns {
+		ownBuckets += fmt.Sprintf(`,
+        "%s",
+        "%s/*"`, bucketArn, bucketArn)
+	}
+
+	ownBucketsStatement := pulumi.String("").ToStringOutput()
+	if len(bucketArns) > 0 {
+		ownBucketsStatement = pulumi.Sprintf(`,
+    {
+      "Sid": "AllowOwnBuckets",
+      "Effect": "Allow",
+      "Principal": "*",
+      "Action": "s3:*",
+      "Resource": [%s
+      ],
+      "Condition": {
+        %s
+      }
+    }`, ownBuckets[1:], principalCondition(account, orgId))
+	}
+
+	return pulumi.Sprintf(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "AllowEcrLayers",
+      "Effect": "Allow",
+      "Principal": "*",
+      "Action": "s3:GetObject",
+      "Resource": "arn:%s:s3:::prod-%s-starport-layer-bucket/*"
+    }%s
+  ]
+}`, partition, region, ownBucketsStatement)
+}
+
+// newEndpointSecurityGroup allows https from every VPC CIDR to the interface endpoints, IPv6 included.
+// The VPC data source only has the first IPv6 association, ipv6CidrBlocks adds the others
+func newEndpointSecurityGroup(ctx *pulumi.Context, name string, vpcId pulumi.StringInput, ipv6CidrBlocks pulumi.StringArrayInput, parent pulumi.Resource) (*ec2.SecurityGroup, error) {
+	vpc := ec2.LookupVpcOutput(ctx, ec2.LookupVpcOutputArgs{
+		Id: vpcId.ToStringOutput().ToStringPtrOutput(),
+	})
+
+	// secondary CIDRs (pod subnets of custom networking...) come as associations next to the primary one
+	vpcCidrs := pulumi.All(vpc.CidrBlock(), vpc.CidrBlockAssociations()).ApplyT(func(all []interface{}) []string {
+		cidrs := []string{all[0].(string)}
+		for _, association := range all[1].([]ec2.GetVpcCidrBlockAssociation) {
+			cidrs = appendMissing(cidrs, association.CidrBlock)
+		}
+		return cidrs
+	}).(pulumi.StringArrayOutput)
+
+	if ipv6CidrBlocks == nil {
+		ipv6CidrBlocks = pulumi.StringArray{}
+	}
+	vpcIpv6Cidrs := pulumi.All(vpc.Ipv6CidrBlock(), ipv6CidrBlocks).ApplyT(func(all []interface{}) []string {
+		cidrs := []string{}
+		if primary := all[0].(string); primary != "" {
+			cidrs = append(cidrs, primary)
+		}
+		return appendMissing(cidrs, all[1].([]string)...)
+	}).(pulumi.StringArrayOutput)
+
+	return ec2.NewSecurityGroup(ctx, fmt.Sprintf("%s-endpoints-security-group", name), &ec2.SecurityGroupArgs{
+		Description: pulumi.StringPtr("https from the vpc to interface endpoints"),
+		VpcId:       vpcId.ToStringOutput().ToStringPtrOutput(),
+		Ingress: ec2.SecurityGroupIngressArray{
+			ec2.SecurityGroupIngressArgs{
+				Description:    pulumi.StringPtr("https from the vpc"),
+				Protocol:       pulumi.String("tcp"),
+				FromPort:       pulumi.Int(443),
+				ToPort:         pulumi.Int(443),
+				CidrBlocks:     vpcCidrs,
+				Ipv6CidrBlocks: vpcIpv6Cidrs,
+			},
+		},
+		Tags: pulumi.StringMap{
+			"Name": pulumi.String(fmt.Sprintf("%s-endpoints", name)),
+		},
+	}, pulumi.Parent(parent))
+}
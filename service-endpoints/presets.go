@@ -0,0 +1,151 @@
+package network
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// VpcEndpointsArgs.Preset values
+const (
+	// PresetEksPrivate covers nodes and the components of this repo in subnets without NAT
+	PresetEksPrivate = "eks-private"
+)
+
+// VpcEndpointsArgs.Components values
+const (
+	ComponentNodes              = "nodes"
+	ComponentContainerLogs      = "container-logs"
+	ComponentEbsController      = "ebs-controller"
+	ComponentElbController      = "elb-controller"
+	ComponentEfsController      = "efs-controller"
+	ComponentS3Mountpoint       = "s3-mountpoint"
+	ComponentKarpenter          = "karpenter"
+	ComponentClusterAutoscaling = "cluster-autoscaling"
+	ComponentPodIdentity        = "pod-identity"
+)
+
+type endpointPreset struct {
+	gateway    []string
+	interfaces []string
+}
+
+var presets = map[string]endpointPreset{
+	PresetEksPrivate: {
+		gateway: []string{"s3"},
+		interfaces: []string{
+			"ecr.api", "ecr.dkr", "sts", "ec2", "logs",
+			"ssm", "ssmmessages", "ec2messages",
+			"elasticloadbalancing", "autoscaling", "sqs", "eks-auth",
+		},
+	},
+}
+
+// services each component calls from inside the VPC, s3 being the ECR layer download
+// https://docs.aws.amazon.com/eks/latest/userguide/private-clusters.html
+var componentServices = map[string][]string{
+	ComponentNodes:              {"ecr.api", "ecr.dkr", "s3", "sts", "ec2", "ssm", "ssmmessages", "ec2messages"},
+	ComponentContainerLogs:      {"logs"},
+	ComponentEbsController:      {"ec2", "sts"},
+	ComponentElbController:      {"elasticloadbalancing", "ec2", "sts"},
+	ComponentEfsController:      {"elasticfilesystem", "sts"},
+	ComponentS3Mountpoint:       {"s3", "sts"},
+	ComponentKarpenter:          {"ec2", "ssm", "sqs", "sts"},
+	ComponentClusterAutoscaling: {"autoscaling", "ec2", "sts"},
+	ComponentPodIdentity:        {"eks-auth"},
+}
+
+// expandPreset adds the preset services to the explicit ones, explicit services keep their order
+func expandPreset(args *VpcEndpointsArgs) (gateway []string, interfaces []string, err error) {
+	gateway = appendMissing(nil, args.GatewayEndpointServices...)
+	interfaces = appendMissing(nil, args.InterfaceEndpointServices...)
+
+	if args.Preset == "" {
+		return gateway, interfaces, nil
+	}
+
+	preset, ok := presets[args.Preset]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown preset %q, must be one of %v", args.Preset, presetNames())
+	}
+
+	gateway = appendMissing(gateway, preset.gateway...)
+	// a service is either a gateway or an interface endpoint here, not both
+	for _, service := range preset.interfaces {
+		if !slices.Contains(gateway, service) {
+			interfaces = appendMissing(interfaces, service)
+		}
+	}
+	return gateway, interfaces, nil
+}
+
+// uncoveredServices lists, per component, the services it needs that have no endpoint
+func uncoveredServices(components []string, services []string) (map[string][]string, error) {
+	uncovered := map[string][]string{}
+	for _, component := range components {
+		required, ok := componentServices[component]
+		if !ok {
+			return nil, fmt.Errorf("unknown component %q", component)
+		}
+		for _, service := range required {
+			if !slices.Contains(services, service) {
+				uncovered[component] = append(uncovered[component], service)
+			}
+		}
+	}
+	return uncovered, nil
+}
+
+// CoversNodes tells if the endpoints, preset included, let nodes bootstrap without NAT.
+// A private-only API endpoint relies on it
+func (args *VpcEndpointsArgs) CoversNodes() (bool, error) {
+	gatewayServices, interfaceServices, err := expandPreset(args)
+	if err != nil {
+		return false, err
+	}
+
+	uncovered, err := uncoveredServices([]string{ComponentNodes}, append(gatewayServices, interfaceServices...))
+	if err != nil {
+		return false, err
+	}
+	return len(uncovered) == 0, nil
+}
+
+// warnUncovered only logs on preview, nodes or pods missing an endpoint hang at bootstrap instead of failing the update
+func warnUncovered(ctx *pulumi.Context, name string, uncovered map[string][]string, parent pulumi.Resource) {
+	if !ctx.DryRun() {
+		return
+	}
+
+	components := make([]string, 0, len(uncovered))
+	for component := range uncovered {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	for _, component := range components {
+		_ = ctx.Log.Warn(fmt.Sprintf("%s: %s needs vpc endpoints for %s, without NAT its calls will time out",
+			name, component, strings.Join(uncovered[component], ", ")), &pulumi.LogArgs{Resource: parent})
+	}
+}
+
+func presetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func appendMissing(list []string, values ...string) []string {
+	for _, value := range values {
+		if !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}